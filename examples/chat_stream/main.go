@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/phaedrusllc/ollama-go/examples/internal/envutil"
 	ollama "github.com/phaedrusllc/ollama-go/ollama"
@@ -21,18 +20,10 @@ func main() {
 		fmt.Println("ERROR:", err)
 		return
 	}
-	defer s.Close()
-	var acc strings.Builder
-	for {
-		part, err := s.Recv()
-		if err == ollama.EOF {
-			break
-		}
-		if err != nil {
-			fmt.Println("ERROR:", err)
-			return
-		}
-		acc.WriteString(part.Message.GetContent())
+	out, err := s.Collect()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		return
 	}
-	fmt.Println("CHAT_STREAM:", acc.String())
+	fmt.Println("CHAT_STREAM:", out.Message.GetContent())
 }
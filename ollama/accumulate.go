@@ -0,0 +1,166 @@
+package ollama
+
+import "strings"
+
+// ChatAccumulator merges streamed ChatResponse chunks into one response:
+// content and thinking are concatenated, tool calls appended, and the
+// metrics and done_reason of the final chunk kept. The zero value is ready
+// to use.
+type ChatAccumulator struct {
+	content  strings.Builder
+	thinking strings.Builder
+	resp     ChatResponse
+	chunks   int
+}
+
+// Add merges one chunk.
+func (a *ChatAccumulator) Add(chunk *ChatResponse) {
+	a.chunks++
+	mergeBase(&a.resp.BaseGenerateResponse, &chunk.BaseGenerateResponse)
+	m := &chunk.Message
+	if m.Role != "" {
+		a.resp.Message.Role = m.Role
+	}
+	if m.Content != nil {
+		a.content.WriteString(*m.Content)
+		if a.resp.Message.Content == nil {
+			a.resp.Message.Content = StrPtr("")
+		}
+	}
+	if m.Thinking != nil {
+		a.thinking.WriteString(*m.Thinking)
+		if a.resp.Message.Thinking == nil {
+			a.resp.Message.Thinking = StrPtr("")
+		}
+	}
+	if m.ToolName != nil {
+		a.resp.Message.ToolName = m.ToolName
+	}
+	a.resp.Message.ToolCalls = append(a.resp.Message.ToolCalls, m.ToolCalls...)
+}
+
+// Chunks returns how many chunks were added.
+func (a *ChatAccumulator) Chunks() int { return a.chunks }
+
+// Response returns the merged response so far. Content and Thinking are nil
+// only if no chunk carried them.
+func (a *ChatAccumulator) Response() *ChatResponse {
+	out := a.resp
+	if out.Message.Content != nil {
+		out.Message.Content = StrPtr(a.content.String())
+	}
+	if out.Message.Thinking != nil {
+		out.Message.Thinking = StrPtr(a.thinking.String())
+	}
+	out.Message.ToolCalls = append([]ToolCall(nil), a.resp.Message.ToolCalls...)
+	return &out
+}
+
+// GenerateAccumulator merges streamed GenerateResponse chunks into one
+// response: response and thinking text are concatenated and the final
+// chunk's metrics, done_reason and Context kept. The zero value is ready to
+// use.
+type GenerateAccumulator struct {
+	response strings.Builder
+	thinking strings.Builder
+	resp     GenerateResponse
+	chunks   int
+}
+
+// Add merges one chunk.
+func (a *GenerateAccumulator) Add(chunk *GenerateResponse) {
+	a.chunks++
+	mergeBase(&a.resp.BaseGenerateResponse, &chunk.BaseGenerateResponse)
+	a.response.WriteString(chunk.Response)
+	if chunk.Thinking != nil {
+		a.thinking.WriteString(*chunk.Thinking)
+		if a.resp.Thinking == nil {
+			a.resp.Thinking = StrPtr("")
+		}
+	}
+	if len(chunk.Context) > 0 {
+		a.resp.Context = chunk.Context
+	}
+}
+
+// Chunks returns how many chunks were added.
+func (a *GenerateAccumulator) Chunks() int { return a.chunks }
+
+// Response returns the merged response so far.
+func (a *GenerateAccumulator) Response() *GenerateResponse {
+	out := a.resp
+	out.Response = a.response.String()
+	if out.Thinking != nil {
+		out.Thinking = StrPtr(a.thinking.String())
+	}
+	return &out
+}
+
+// mergeBase copies every field set in src over dst, so the last chunk's
+// metrics win.
+func mergeBase(dst, src *BaseGenerateResponse) {
+	if src.Model != nil {
+		dst.Model = src.Model
+	}
+	if src.CreatedAt != nil {
+		dst.CreatedAt = src.CreatedAt
+	}
+	if src.Done != nil {
+		dst.Done = src.Done
+	}
+	if src.DoneReason != nil {
+		dst.DoneReason = src.DoneReason
+	}
+	if src.TotalDuration != nil {
+		dst.TotalDuration = src.TotalDuration
+	}
+	if src.LoadDuration != nil {
+		dst.LoadDuration = src.LoadDuration
+	}
+	if src.PromptEvalCount != nil {
+		dst.PromptEvalCount = src.PromptEvalCount
+	}
+	if src.PromptEvalDur != nil {
+		dst.PromptEvalDur = src.PromptEvalDur
+	}
+	if src.EvalCount != nil {
+		dst.EvalCount = src.EvalCount
+	}
+	if src.EvalDuration != nil {
+		dst.EvalDuration = src.EvalDuration
+	}
+}
+
+// Collect reads the stream to the end, closes it and returns the merged
+// result: chat and generate streams are merged with ChatAccumulator and
+// GenerateAccumulator, other streams yield their last chunk. If the stream
+// fails, Collect returns what was merged so far along with the error.
+func (s *Stream[T]) Collect() (*T, error) {
+	defer func() { _ = s.Close() }()
+	var add func(*T)
+	var result func() *T
+	switch any(s).(type) {
+	case *Stream[ChatResponse]:
+		var a ChatAccumulator
+		add = func(v *T) { a.Add(any(v).(*ChatResponse)) }
+		result = func() *T { return any(a.Response()).(*T) }
+	case *Stream[GenerateResponse]:
+		var a GenerateAccumulator
+		add = func(v *T) { a.Add(any(v).(*GenerateResponse)) }
+		result = func() *T { return any(a.Response()).(*T) }
+	default:
+		var last *T
+		add = func(v *T) { last = v }
+		result = func() *T { return last }
+	}
+	for {
+		v, err := s.Recv()
+		if err == EOF {
+			return result(), nil
+		}
+		if err != nil {
+			return result(), err
+		}
+		add(v)
+	}
+}
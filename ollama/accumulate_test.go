@@ -0,0 +1,77 @@
+package ollama
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCollect_Chat(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"model":"m","message":{"role":"assistant","content":"","thinking":"Let me "}}
+{"model":"m","message":{"role":"assistant","content":"","thinking":"think."}}
+{"model":"m","message":{"role":"assistant","content":"Hello"}}
+{"model":"m","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"Paris"}}}]}}
+{"model":"m","message":{"role":"assistant","content":", world"}}
+{"model":"m","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","eval_count":5,"total_duration":99}
+`)
+	})
+	defer srv.Close()
+	s, err := c.ChatStream(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := s.Collect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Message.Role != "assistant" || out.Message.GetContent() != "Hello, world" || *out.Message.Thinking != "Let me think." {
+		t.Fatalf("message: %+v", out.Message)
+	}
+	if len(out.Message.ToolCalls) != 1 || out.Message.ToolCalls[0].Function.Name != "get_weather" {
+		t.Fatalf("tool calls: %+v", out.Message.ToolCalls)
+	}
+	if !*out.Done || *out.DoneReason != "stop" || *out.EvalCount != 5 || *out.TotalDuration != 99 || *out.Model != "m" {
+		t.Fatalf("metrics: %+v", out.BaseGenerateResponse)
+	}
+}
+
+func TestCollect_GenerateKeepsContextAndPartialOnError(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"a\"}\n{\"response\":\"b\",\"thinking\":\"t\"}\n{\"response\":\"\",\"done\":true,\"context\":[1,2,3],\"prompt_eval_count\":4}\n")
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := s.Collect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Response != "ab" || *out.Thinking != "t" || !reflect.DeepEqual(out.Context, []int{1, 2, 3}) || *out.PromptEvalCount != 4 {
+		t.Fatalf("unexpected: %+v", out)
+	}
+
+	var a GenerateAccumulator
+	a.Add(&GenerateResponse{Response: "par"})
+	a.Add(&GenerateResponse{Response: "tial"})
+	if got := a.Response(); got.Response != "partial" || got.Thinking != nil || got.Done != nil || a.Chunks() != 2 {
+		t.Fatalf("accumulator: %+v", got)
+	}
+
+	srv2, c2 := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"so far\"}\n{\"error\":\"runner crashed\"}\n")
+	})
+	defer srv2.Close()
+	s, err = c2.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = s.Collect()
+	if err == nil || out.Response != "so far" {
+		t.Fatalf("out=%+v err=%v", out, err)
+	}
+}
@@ -0,0 +1,302 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultKeepAlive mirrors the server's default keep_alive; a model that just
+// served a request is assumed resident for this long.
+const defaultKeepAlive = 5 * time.Minute
+
+// WithModelAffinity routes Generate, Chat, Embed and Embeddings calls on a
+// multi-host client to the host that already has the model loaded, using each
+// host's /api/ps and /api/tags results cached for ttl and refreshed in the
+// background. When no host has the model resident, the host that has it
+// installed and the least VRAM in use is preferred; otherwise the balance
+// strategy decides. A host that does not answer the refresh is marked down.
+func WithModelAffinity(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		if ttl <= 0 {
+			ttl = 30 * time.Second
+		}
+		c.affinityTTL = ttl
+	}
+}
+
+// affinityPaths are the endpoints whose latency depends on model residency.
+var affinityPaths = map[string]bool{
+	"/api/generate":   true,
+	"/api/chat":       true,
+	"/api/embed":      true,
+	"/api/embeddings": true,
+}
+
+// affinityProbeTimeout bounds one host's /api/ps and /api/tags refresh, and
+// affinityFirstWait how long a call waits for a host's first refresh before
+// routing without it.
+const (
+	affinityProbeTimeout = 2 * time.Second
+	affinityFirstWait    = 100 * time.Millisecond
+)
+
+type residentModel struct {
+	expiresAt time.Time
+	sizeVRAM  int64
+}
+
+// hostModels caches one host's loaded and installed models.
+type hostModels struct {
+	fetching  bool          // a refresh is running
+	ready     chan struct{} // closed when the first refresh finishes
+	fetchedAt time.Time
+	loaded    map[string]residentModel
+	installed map[string]bool
+}
+
+// affinityRouter routes on cached /api/ps and /api/tags results. Stale
+// entries are refreshed in the background, one probe per host with its own
+// timeout, so a slow host never holds up a call; a host whose probe cannot
+// connect or times out is marked down.
+type affinityRouter struct {
+	ttl      time.Duration
+	timeout  time.Duration
+	query    func(ctx context.Context, base string) (*ProcessResponse, *ListResponse, error)
+	markDown func(h *poolHost)
+
+	mu    sync.Mutex
+	hosts map[*poolHost]*hostModels
+}
+
+func newAffinityRouter(c *Client, ttl time.Duration) *affinityRouter {
+	return &affinityRouter{
+		ttl:     ttl,
+		timeout: affinityProbeTimeout,
+		query: func(ctx context.Context, base string) (*ProcessResponse, *ListResponse, error) {
+			ps, err := probeJSON[ProcessResponse](ctx, c, base, "/api/ps")
+			if err != nil {
+				return nil, nil, err
+			}
+			tags, err := probeJSON[ListResponse](ctx, c, base, "/api/tags")
+			if err != nil {
+				return nil, nil, err
+			}
+			return ps, tags, nil
+		},
+		markDown: c.pool.markDown,
+		hosts:    map[*poolHost]*hostModels{},
+	}
+}
+
+// probeJSON GETs path from base with the client's credentials, bypassing
+// middleware, retries and instrumentation.
+func probeJSON[T any](ctx context.Context, c *Client, base, path string) (*T, error) {
+	resp, err := c.authChain()(ctx, &Request{Method: http.MethodGet, Path: path, Host: base})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, newResponseError(resp.StatusCode, b)
+	}
+	out := new(T)
+	if err := json.Unmarshal(b, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// hostClient returns a single-host copy of c pinned to base, sharing its
+// transport, headers and middleware.
+func (c *Client) hostClient(base string) *Client {
+	cc := *c
+	cc.base = base
+	cc.pool = nil
+	return &cc
+}
+
+// choose returns the preferred candidate for model, or nil when the cache
+// gives no preference. It starts refreshes for stale hosts and waits at most
+// affinityFirstWait, and only for hosts that have never been refreshed.
+func (a *affinityRouter) choose(ctx context.Context, candidates []*poolHost, model string) *poolHost {
+	model = normalizeModel(model)
+	entries := make([]*hostModels, len(candidates))
+	var pending []<-chan struct{}
+	a.mu.Lock()
+	for i, h := range candidates {
+		hm := a.entry(h)
+		entries[i] = hm
+		if time.Since(hm.fetchedAt) >= a.ttl {
+			a.refresh(h, hm)
+		}
+		if hm.fetchedAt.IsZero() {
+			pending = append(pending, hm.ready)
+		}
+	}
+	a.mu.Unlock()
+	if len(pending) > 0 {
+		t := time.NewTimer(affinityFirstWait)
+	wait:
+		for _, ch := range pending {
+			select {
+			case <-ch:
+			case <-t.C:
+				break wait
+			case <-ctx.Done():
+				break wait
+			}
+		}
+		t.Stop()
+	}
+
+	now := time.Now()
+	var resident, installed []*poolHost
+	vram := map[*poolHost]int64{}
+	a.mu.Lock()
+	for i, h := range candidates {
+		hm := entries[i]
+		if m, ok := hm.loaded[model]; ok && m.expiresAt.After(now) {
+			resident = append(resident, h)
+		} else if hm.installed[model] {
+			installed = append(installed, h)
+		}
+		for _, m := range hm.loaded {
+			if m.expiresAt.After(now) {
+				vram[h] += m.sizeVRAM
+			}
+		}
+	}
+	a.mu.Unlock()
+	if len(resident) > 0 {
+		return leastBusy(resident, nil)
+	}
+	if len(installed) > 0 {
+		return leastBusy(installed, vram)
+	}
+	return nil
+}
+
+// leastBusy picks the host with the least VRAM in use (when given), then the
+// fewest in-flight requests.
+func leastBusy(hs []*poolHost, vram map[*poolHost]int64) *poolHost {
+	best := hs[0]
+	for _, h := range hs[1:] {
+		switch {
+		case vram[h] < vram[best]:
+			best = h
+		case vram[h] == vram[best] && h.inflight.Load() < best.inflight.Load():
+			best = h
+		}
+	}
+	return best
+}
+
+// entry returns h's cache entry, creating it. a.mu must be held.
+func (a *affinityRouter) entry(h *poolHost) *hostModels {
+	hm := a.hosts[h]
+	if hm == nil {
+		hm = &hostModels{ready: make(chan struct{}), loaded: map[string]residentModel{}, installed: map[string]bool{}}
+		a.hosts[h] = hm
+	}
+	return hm
+}
+
+// refresh re-queries h in the background unless a refresh is already
+// running. Failed queries keep the previous data until the next ttl. a.mu
+// must be held.
+func (a *affinityRouter) refresh(h *poolHost, hm *hostModels) {
+	if hm.fetching {
+		return
+	}
+	hm.fetching = true
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+		ps, tags, err := a.query(ctx, h.base)
+		cancel()
+		var re *ResponseError
+		if err != nil && !errors.As(err, &re) {
+			// no answer at all, as opposed to an error status
+			a.markDown(h)
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if hm.fetchedAt.IsZero() {
+			defer close(hm.ready)
+		}
+		hm.fetching = false
+		hm.fetchedAt = time.Now()
+		if err != nil {
+			return
+		}
+		hm.loaded = map[string]residentModel{}
+		for _, m := range ps.Models {
+			name := modelName(m.Model, m.Name)
+			if name == "" {
+				continue
+			}
+			rm := residentModel{expiresAt: hm.fetchedAt.Add(defaultKeepAlive)}
+			if m.ExpiresAt != nil {
+				rm.expiresAt = *m.ExpiresAt
+			}
+			if m.SizeVRAM != nil {
+				rm.sizeVRAM = *m.SizeVRAM
+			}
+			hm.loaded[name] = rm
+		}
+		hm.installed = map[string]bool{}
+		for _, m := range tags.Models {
+			if name := modelName(m.Model, nil); name != "" {
+				hm.installed[name] = true
+			}
+		}
+	}()
+}
+
+// noteServed records that h just served model, so it stays preferred until
+// the next refresh replaces the guess with the server's view.
+func (a *affinityRouter) noteServed(h *poolHost, model string) {
+	model = normalizeModel(model)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	hm := a.hosts[h]
+	if hm == nil {
+		return
+	}
+	if m, ok := hm.loaded[model]; ok && m.expiresAt.After(time.Now()) {
+		return
+	}
+	hm.loaded[model] = residentModel{expiresAt: time.Now().Add(defaultKeepAlive)}
+	hm.installed[model] = true
+}
+
+func modelName(model, name *string) string {
+	switch {
+	case model != nil && *model != "":
+		return normalizeModel(*model)
+	case name != nil:
+		return normalizeModel(*name)
+	}
+	return ""
+}
+
+// normalizeModel appends the implicit ":latest" tag so "llama3" matches the
+// "llama3:latest" reported by the server.
+func normalizeModel(m string) string {
+	if m == "" {
+		return ""
+	}
+	if i := strings.LastIndex(m, "/"); !strings.Contains(m[i+1:], ":") {
+		return m + ":latest"
+	}
+	return m
+}
@@ -0,0 +1,167 @@
+package ollama
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// modelsTransport serves /api/ps and /api/tags per host and echoes the host
+// name from inference endpoints.
+type modelsTransport struct {
+	mu   sync.Mutex
+	ps   map[string]string
+	tags map[string]string
+	seen map[string]int
+}
+
+func (t *modelsTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	host := r.URL.Hostname()
+	t.seen[r.URL.Path]++
+	body := `{"models":[]}`
+	switch r.URL.Path {
+	case "/api/ps":
+		if v, ok := t.ps[host]; ok {
+			body = v
+		}
+	case "/api/tags":
+		if v, ok := t.tags[host]; ok {
+			body = v
+		}
+	default:
+		body = `{"response":"` + host + `","done":true}`
+	}
+	return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+}
+
+func TestAffinity_PrefersResidentHost(t *testing.T) {
+	exp := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	rt := &modelsTransport{
+		ps: map[string]string{
+			"b": `{"models":[{"model":"llama3:latest","expires_at":"` + exp + `","size_vram":100}]}`,
+		},
+		tags: map[string]string{
+			"a": `{"models":[{"model":"llama3:latest"}]}`,
+			"b": `{"models":[{"model":"llama3:latest"}]}`,
+		},
+		seen: map[string]int{},
+	}
+	c := NewPool([]string{"a:1", "b:1", "c:1"}, WithHTTPClient(&http.Client{Transport: rt}), WithModelAffinity(time.Minute))
+	defer func() { _ = c.Close() }()
+	for i := 0; i < 4; i++ {
+		out, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "llama3"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.Response != "b" {
+			t.Fatalf("request %d routed to %q, want b", i, out.Response)
+		}
+	}
+	// cached for the ttl: one ps and one tags request per host
+	if rt.seen["/api/ps"] != 3 || rt.seen["/api/tags"] != 3 {
+		t.Fatalf("unexpected refreshes: %v", rt.seen)
+	}
+}
+
+func TestAffinity_FallsBackToInstalledWithMostCapacity(t *testing.T) {
+	exp := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	rt := &modelsTransport{
+		ps: map[string]string{
+			"a": `{"models":[{"model":"other:latest","expires_at":"` + exp + `","size_vram":8000}]}`,
+			"b": `{"models":[{"model":"small:latest","expires_at":"` + exp + `","size_vram":1000}]}`,
+		},
+		tags: map[string]string{
+			"a": `{"models":[{"model":"qwen:7b"}]}`,
+			"b": `{"models":[{"model":"qwen:7b"}]}`,
+			"c": `{"models":[]}`,
+		},
+		seen: map[string]int{},
+	}
+	c := NewPool([]string{"a:1", "b:1", "c:1"}, WithHTTPClient(&http.Client{Transport: rt}), WithModelAffinity(time.Minute))
+	defer func() { _ = c.Close() }()
+	out, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "qwen:7b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Response != "b" {
+		t.Fatalf("routed to %q, want b", out.Response)
+	}
+}
+
+func TestAffinity_StickyAfterServing(t *testing.T) {
+	rt := &modelsTransport{ps: map[string]string{}, tags: map[string]string{}, seen: map[string]int{}}
+	c := NewPool([]string{"a:1", "b:1"}, WithHTTPClient(&http.Client{Transport: rt}), WithModelAffinity(time.Minute))
+	defer func() { _ = c.Close() }()
+	first := generateHost(t, c)
+	for i := 0; i < 3; i++ {
+		if h := generateHost(t, c); h != first {
+			t.Fatalf("request %d went to %q, want %q", i, h, first)
+		}
+	}
+}
+
+func TestNormalizeModel(t *testing.T) {
+	cases := map[string]string{
+		"llama3":                  "llama3:latest",
+		"llama3:8b":               "llama3:8b",
+		"registry:5000/org/model": "registry:5000/org/model:latest",
+		"":                        "",
+	}
+	for in, want := range cases {
+		if got := normalizeModel(in); got != want {
+			t.Errorf("normalizeModel(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestAffinity_HungHostDoesNotBlock(t *testing.T) {
+	stop := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-stop:
+		}
+	}))
+	defer hung.Close()
+	defer close(stop)
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = io.WriteString(w, `{"models":[{"model":"llama3:latest"}]}`)
+		case "/api/ps":
+			_, _ = io.WriteString(w, `{"models":[]}`)
+		default:
+			_, _ = io.WriteString(w, `{"response":"healthy","done":true}`)
+		}
+	}))
+	defer healthy.Close()
+
+	c := NewPool([]string{hung.URL, healthy.URL}, WithModelAffinity(time.Minute))
+	defer func() { _ = c.Close() }()
+	c.pool.affinity.timeout = 100 * time.Millisecond
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		out, err := c.Generate(ctx, &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "llama3"}})
+		cancel()
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if out.Response != "healthy" {
+			t.Fatalf("request %d routed to %q", i, out.Response)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Hosts()[0].Healthy {
+		if time.Now().After(deadline) {
+			t.Fatal("hung host not marked down")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,129 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies bearer tokens for the Authorization header.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenInvalidator is implemented by token sources that cache tokens. After a
+// 401 response the client calls Invalidate with the rejected token, fetches a
+// fresh one and retries the request once.
+type TokenInvalidator interface {
+	Invalidate(token string)
+}
+
+// TokenSourceFunc adapts a function to TokenSource.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token calls f.
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) { return f(ctx) }
+
+// StaticTokenSource always returns key.
+func StaticTokenSource(key string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (string, error) { return key, nil })
+}
+
+// WithAPIKey sends key as a bearer token on every request.
+func WithAPIKey(key string) ClientOption { return WithTokenSource(StaticTokenSource(key)) }
+
+// WithTokenSource sends tokens from ts as bearer tokens on every request.
+// It takes precedence over OLLAMA_API_KEY.
+func WithTokenSource(ts TokenSource) ClientOption {
+	return func(c *Client) { c.tokens = ts }
+}
+
+// CachedTokenSource caches tokens from fetch until shortly before they expire
+// and refetches after Invalidate.
+type CachedTokenSource struct {
+	fetch  func(ctx context.Context) (token string, expiry time.Time, err error)
+	leeway time.Duration
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewCachedTokenSource wraps fetch, which returns a token and its expiry. A
+// zero expiry means the token is valid until invalidated.
+func NewCachedTokenSource(fetch func(ctx context.Context) (string, time.Time, error)) *CachedTokenSource {
+	return &CachedTokenSource{fetch: fetch, leeway: 10 * time.Second}
+}
+
+// Token returns the cached token or fetches a new one.
+func (s *CachedTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && (s.expiry.IsZero() || time.Now().Add(s.leeway).Before(s.expiry)) {
+		return s.token, nil
+	}
+	tok, exp, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expiry = tok, exp
+	return tok, nil
+}
+
+// Invalidate drops the cached token if it is still the rejected one.
+func (s *CachedTokenSource) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = ""
+	}
+}
+
+// authenticate is the innermost middleware: it attaches the bearer token just
+// before sending, so user middleware and logs never see it, retries once on
+// 401 when the source can refresh, and scrubs the token from error bodies.
+func (c *Client) authenticate(next RoundTripFunc) RoundTripFunc {
+	return func(ctx context.Context, req *Request) (*http.Response, error) {
+		tok, err := c.tokens.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := next(ctx, withBearer(req, tok))
+		if err != nil {
+			return nil, err
+		}
+		if inv, ok := c.tokens.(TokenInvalidator); ok && resp.StatusCode == http.StatusUnauthorized && rewind(req.Body) {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			inv.Invalidate(tok)
+			if tok, err = c.tokens.Token(ctx); err != nil {
+				return nil, err
+			}
+			if resp, err = next(ctx, withBearer(req, tok)); err != nil {
+				return nil, err
+			}
+		}
+		if resp.StatusCode >= 400 && tok != "" {
+			b, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(bytes.ReplaceAll(b, []byte(tok), []byte("[REDACTED]"))))
+		}
+		return resp, nil
+	}
+}
+
+// withBearer returns a copy of req carrying tok in its Authorization header.
+func withBearer(req *Request, tok string) *Request {
+	r := *req
+	r.Header = req.Header.Clone()
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	if tok != "" {
+		r.Header.Set("Authorization", "Bearer "+tok)
+	}
+	return &r
+}
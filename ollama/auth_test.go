@@ -0,0 +1,122 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithAPIKey_SendsBearer(t *testing.T) {
+	var auth string
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_, _ = io.WriteString(w, `{"models":[]}`)
+	})
+	defer srv.Close()
+	WithAPIKey("secret")(c)
+	if _, err := c.List(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer secret" {
+		t.Fatalf("auth=%q", auth)
+	}
+}
+
+func TestAPIKey_FromEnv(t *testing.T) {
+	old := os.Getenv("OLLAMA_API_KEY")
+	defer func() { _ = os.Setenv("OLLAMA_API_KEY", old) }()
+	_ = os.Setenv("OLLAMA_API_KEY", "envkey")
+	c := NewClient("")
+	if c.tokens == nil {
+		t.Fatal("expected token source from env")
+	}
+	if tok, _ := c.tokens.Token(context.Background()); tok != "envkey" {
+		t.Fatalf("tok=%q", tok)
+	}
+	if s := fmt.Sprintf("%+v", c); strings.Contains(s, "envkey") {
+		t.Fatalf("key leaked in debug output: %s", s)
+	}
+}
+
+func TestTokenSource_RefreshOn401(t *testing.T) {
+	var calls atomic.Int32
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		b, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(b), `"model":"m"`) {
+			t.Errorf("body not replayed: %q", b)
+		}
+		if r.Header.Get("Authorization") != "Bearer tok-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"response":"ok"}`)
+	})
+	defer srv.Close()
+	var fetches atomic.Int32
+	ts := NewCachedTokenSource(func(context.Context) (string, time.Time, error) {
+		n := fetches.Add(1)
+		return fmt.Sprintf("tok-%d", n), time.Now().Add(time.Hour), nil
+	})
+	WithTokenSource(ts)(c)
+	out, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Response != "ok" || calls.Load() != 2 || fetches.Load() != 2 {
+		t.Fatalf("resp=%q calls=%d fetches=%d", out.Response, calls.Load(), fetches.Load())
+	}
+	// the refreshed token stays cached
+	if _, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}); err != nil {
+		t.Fatal(err)
+	}
+	if fetches.Load() != 2 {
+		t.Fatalf("fetches=%d", fetches.Load())
+	}
+}
+
+func TestTokenSource_StaticDoesNotRetry401(t *testing.T) {
+	var calls atomic.Int32
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"error":"invalid key hunter2"}`)
+	})
+	defer srv.Close()
+	WithAPIKey("hunter2")(c)
+	_, err := c.List(context.Background())
+	var re *ResponseError
+	if !errors.As(err, &re) || re.StatusCode != 401 {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if strings.Contains(re.Error(), "hunter2") {
+		t.Fatalf("credential leaked: %v", re)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls=%d", calls.Load())
+	}
+}
+
+func TestTokenSource_MiddlewareDoesNotSeeToken(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, `{}`) })
+	defer srv.Close()
+	WithAPIKey("secret")(c)
+	WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "" {
+				t.Error("middleware saw credentials")
+			}
+			return next(ctx, req)
+		}
+	})(c)
+	if _, err := c.PS(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,174 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// WithAutoPull pulls a missing model the first time Generate, Chat, Embed or
+// their streaming forms fail with ErrModelNotFound, then retries the call
+// once. progress, which may be nil, receives each pull update. Concurrent
+// calls for the same model share one pull. On a multi-host client the pull
+// and the retry go to the host that reported the model missing.
+func WithAutoPull(progress func(model string, p *ProgressResponse)) ClientOption {
+	return func(c *Client) {
+		if progress == nil {
+			progress = func(string, *ProgressResponse) {}
+		}
+		c.autoPull = progress
+	}
+}
+
+// autoPullPaths are the endpoints that trigger WithAutoPull.
+var autoPullPaths = map[string]bool{
+	"/api/generate":   true,
+	"/api/chat":       true,
+	"/api/embed":      true,
+	"/api/embeddings": true,
+}
+
+// EnsureModel pulls model unless it is already installed, reporting pull
+// progress to progress if non-nil. Concurrent calls for the same model,
+// including auto-pulls, share one pull and each receives its updates from
+// the time it joined; a caller whose ctx ends stops waiting but the pull
+// continues for the others. On a multi-host client the model is checked and
+// pulled on every host concurrently, progress calls are serialized and the
+// hosts' errors are joined.
+func (c *Client) EnsureModel(ctx context.Context, model string, progress func(*ProgressResponse)) error {
+	if err := ensureModel(model); err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = func(*ProgressResponse) {}
+	}
+	if c.pool == nil {
+		return c.ensureOn(ctx, c.base, model, progress)
+	}
+	var mu sync.Mutex
+	serial := func(p *ProgressResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress(p)
+	}
+	errs := make([]error, len(c.pool.hosts))
+	var wg sync.WaitGroup
+	for i, h := range c.pool.hosts {
+		wg.Add(1)
+		go func(i int, base string) {
+			defer wg.Done()
+			if err := c.hostClient(base).ensureOn(ctx, base, model, serial); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", base, err)
+			}
+		}(i, h.base)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// ensureOn checks for and pulls model on a single-host client for host.
+func (c *Client) ensureOn(ctx context.Context, host, model string, progress func(*ProgressResponse)) error {
+	_, err := c.Show(ctx, model)
+	if err == nil || !errors.Is(err, ErrModelNotFound) {
+		return err
+	}
+	return c.pullShared(ctx, host, model, new(int), func(_ string, p *ProgressResponse) { progress(p) })
+}
+
+// pullGroup deduplicates concurrent pulls of the same model to the same host.
+type pullGroup struct {
+	mu    sync.Mutex
+	calls map[string]*pullCall
+}
+
+type pullCall struct {
+	done chan struct{}
+	err  error
+	subs map[any]*pullSub // progress subscribers, guarded by pullGroup.mu
+}
+
+type pullSub struct {
+	progress func(string, *ProgressResponse)
+	waiters  int
+}
+
+// autoPullSub keys the WithAutoPull callback, which all auto-pull waiters
+// share so it sees each update once.
+type autoPullSub struct{}
+
+// pullShared pulls model on host, which c must be pinned to, joining a pull
+// already in progress; progress is subscribed under sub while the
+// caller waits. The pull runs detached from ctx so that one caller giving up
+// does not fail the others.
+func (c *Client) pullShared(ctx context.Context, host, model string, sub any, progress func(string, *ProgressResponse)) error {
+	key := host + " " + normalizeModel(model)
+	g := c.pulls
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		if g.calls == nil {
+			g.calls = map[string]*pullCall{}
+		}
+		call = &pullCall{done: make(chan struct{}), subs: map[any]*pullSub{}}
+		g.calls[key] = call
+		go func() {
+			call.err = c.pull(context.WithoutCancel(ctx), model, func(m string, p *ProgressResponse) {
+				g.mu.Lock()
+				fs := make([]func(string, *ProgressResponse), 0, len(call.subs))
+				for _, s := range call.subs {
+					fs = append(fs, s.progress)
+				}
+				g.mu.Unlock()
+				for _, f := range fs {
+					f(m, p)
+				}
+			})
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	s := call.subs[sub]
+	if s == nil {
+		s = &pullSub{progress: progress}
+		call.subs[sub] = s
+	}
+	s.waiters++
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		if s.waiters--; s.waiters == 0 {
+			delete(call.subs, sub)
+		}
+		g.mu.Unlock()
+	}()
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pull streams a pull to completion. The stream itself fails with
+// ErrIncompleteStream unless it ends with a "success" status.
+func (c *Client) pull(ctx context.Context, model string, progress func(string, *ProgressResponse)) error {
+	s, err := c.PullStream(ctx, &PullRequest{Model: model})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+	for {
+		p, err := s.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		progress(model, p)
+	}
+}
@@ -0,0 +1,230 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pullServer serves chat and show for installed models only, and installs a
+// model after a slow streamed pull.
+type pullServer struct {
+	mu        sync.Mutex
+	installed map[string]bool
+	pulls     atomic.Int32
+	final     string
+}
+
+func (p *pullServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	model := "m:latest"
+	if strings.Contains(string(body), `"other"`) {
+		model = "other:latest"
+	}
+	switch r.URL.Path {
+	case "/api/pull":
+		p.pulls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = io.WriteString(w, "{\"status\":\"pulling manifest\"}\n{\"status\":\"downloading\",\"completed\":5,\"total\":10}\n")
+		_, _ = io.WriteString(w, "{\"status\":\""+p.final+"\"}\n")
+		if p.final == "success" {
+			p.mu.Lock()
+			p.installed[model] = true
+			p.mu.Unlock()
+		}
+		return
+	}
+	p.mu.Lock()
+	ok := p.installed[model]
+	p.mu.Unlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":"model '`+model+`' not found"}`)
+		return
+	}
+	_, _ = io.WriteString(w, `{"message":{"role":"assistant","content":"hi"},"done":true,"modelfile":"x"}`)
+}
+
+func TestAutoPull_DeduplicatesAndRetries(t *testing.T) {
+	ps := &pullServer{installed: map[string]bool{}, final: "success"}
+	srv, c := newTestServer(t, ps.handle)
+	defer srv.Close()
+	var updates atomic.Int32
+	WithAutoPull(func(model string, p *ProgressResponse) {
+		if model == "m" {
+			updates.Add(1)
+		}
+	})(c)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := c.Chat(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}, Messages: []Message{{Role: "user", Content: StrPtr("hi")}}})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if *out.Message.Content != "hi" {
+				t.Errorf("unexpected: %+v", out)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := ps.pulls.Load(); n != 1 {
+		t.Fatalf("pulls=%d, want 1", n)
+	}
+	if updates.Load() != 3 {
+		t.Fatalf("progress updates=%d", updates.Load())
+	}
+}
+
+func TestAutoPull_FailedPullIsReported(t *testing.T) {
+	ps := &pullServer{installed: map[string]bool{}, final: "verifying sha256 digest"}
+	srv, c := newTestServer(t, ps.handle)
+	defer srv.Close()
+	WithAutoPull(nil)(c)
+
+	_, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err == nil || !strings.Contains(err.Error(), "auto-pull m") || !strings.Contains(err.Error(), "without success") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// without the option, not-found errors pass straight through
+	c2 := NewClient(srv.URL)
+	if _, err := c2.Chat(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if ps.pulls.Load() != 1 {
+		t.Fatalf("pulls=%d", ps.pulls.Load())
+	}
+}
+
+func TestEnsureModel(t *testing.T) {
+	ps := &pullServer{installed: map[string]bool{"other:latest": true}, final: "success"}
+	srv, c := newTestServer(t, ps.handle)
+	defer srv.Close()
+	ctx := context.Background()
+
+	if err := c.EnsureModel(ctx, "other", nil); err != nil {
+		t.Fatal(err)
+	}
+	if ps.pulls.Load() != 0 {
+		t.Fatal("installed model was pulled")
+	}
+	var statuses []string
+	if err := c.EnsureModel(ctx, "m", func(p *ProgressResponse) { statuses = append(statuses, *p.Status) }); err != nil {
+		t.Fatal(err)
+	}
+	if ps.pulls.Load() != 1 || strings.Join(statuses, ",") != "pulling manifest,downloading,success" {
+		t.Fatalf("pulls=%d statuses=%v", ps.pulls.Load(), statuses)
+	}
+}
+
+func TestAutoPull_PinsPullToFailingHost(t *testing.T) {
+	a := &pullServer{installed: map[string]bool{}, final: "success"}
+	b := &pullServer{installed: map[string]bool{}, final: "success"}
+	srvA := httptest.NewServer(http.HandlerFunc(a.handle))
+	defer srvA.Close()
+	srvB := httptest.NewServer(http.HandlerFunc(b.handle))
+	defer srvB.Close()
+	c := NewPool([]string{srvA.URL, srvB.URL}, WithAutoPull(nil))
+	defer func() { _ = c.Close() }()
+
+	// round robin sends the first call to A
+	if _, err := c.Chat(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}); err != nil {
+		t.Fatal(err)
+	}
+	if a.pulls.Load() != 1 || b.pulls.Load() != 0 {
+		t.Fatalf("pulls: a=%d b=%d", a.pulls.Load(), b.pulls.Load())
+	}
+}
+
+func TestEnsureModel_JoinersGetProgress(t *testing.T) {
+	ps := &pullServer{installed: map[string]bool{}, final: "success"}
+	srv, c := newTestServer(t, ps.handle)
+	defer srv.Close()
+
+	var mu sync.Mutex
+	got := map[int]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := c.EnsureModel(context.Background(), "m", func(*ProgressResponse) {
+				mu.Lock()
+				got[i]++
+				mu.Unlock()
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if ps.pulls.Load() != 1 || got[0] != 3 || got[1] != 3 {
+		t.Fatalf("pulls=%d progress=%v", ps.pulls.Load(), got)
+	}
+}
+
+func TestEnsureModel_PoolChecksEveryHost(t *testing.T) {
+	a := &pullServer{installed: map[string]bool{"m:latest": true}, final: "success"}
+	b := &pullServer{installed: map[string]bool{}, final: "success"}
+	srvA := httptest.NewServer(http.HandlerFunc(a.handle))
+	defer srvA.Close()
+	srvB := httptest.NewServer(http.HandlerFunc(b.handle))
+	defer srvB.Close()
+	c := NewPool([]string{srvA.URL, srvB.URL})
+	defer func() { _ = c.Close() }()
+
+	for i := 0; i < 2; i++ {
+		if err := c.EnsureModel(context.Background(), "m", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.pulls.Load() != 0 || b.pulls.Load() != 1 {
+		t.Fatalf("pulls: a=%d b=%d", a.pulls.Load(), b.pulls.Load())
+	}
+
+	b.final = "verifying sha256 digest"
+	b.installed = map[string]bool{}
+	err := c.EnsureModel(context.Background(), "m", nil)
+	if err == nil || !strings.Contains(err.Error(), srvB.URL) || strings.Contains(err.Error(), srvA.URL) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestEnsureModel_SharesPullWithAutoPull(t *testing.T) {
+	ps := &pullServer{installed: map[string]bool{}, final: "success"}
+	srv, c := newTestServer(t, ps.handle)
+	defer srv.Close()
+	WithAutoPull(nil)(c)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if _, err := c.Chat(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}); err != nil {
+			t.Error(err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := c.EnsureModel(context.Background(), "m", nil); err != nil {
+			t.Error(err)
+		}
+	}()
+	wg.Wait()
+	if n := ps.pulls.Load(); n != 1 {
+		t.Fatalf("pulls=%d, want 1", n)
+	}
+}
@@ -0,0 +1,238 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is matched by errors returned while a circuit breaker is
+// rejecting calls for a host and model.
+var ErrCircuitOpen = errors.New("ollama: circuit open")
+
+// CircuitOpenError reports which host and model a call was rejected for.
+type CircuitOpenError struct {
+	Host  string
+	Model string
+	// RetryAfter is how long until the breaker lets a trial call through.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for model %q on %s (retry in %s)", e.Model, e.Host, e.RetryAfter.Round(time.Millisecond))
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool { return target == ErrCircuitOpen }
+
+// BreakerState is the state of one circuit.
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// BreakerKey identifies a circuit. Model is empty for calls that do not name
+// a model, such as List and PS.
+type BreakerKey struct {
+	Host  string
+	Model string
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker. Zero fields take defaults.
+type CircuitBreakerConfig struct {
+	// FailureRatio opens the circuit once failures/requests in the current
+	// window reaches it. Default 0.5.
+	FailureRatio float64
+	// MinRequests is the number of calls in a window before the ratio is
+	// evaluated. Default 5.
+	MinRequests int
+	// Window is how long failure counts accumulate before resetting.
+	// Default 1 minute.
+	Window time.Duration
+	// Cooldown is how long the circuit stays open before a single trial call
+	// is let through. Default 30 seconds.
+	Cooldown time.Duration
+	// IsFailure classifies an attempt. By default transport errors and 5xx
+	// responses are failures; caller cancellation never is.
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange, when set, is called after every transition.
+	OnStateChange func(key BreakerKey, from, to BreakerState)
+}
+
+// CircuitBreaker tracks failures per host and model and rejects calls with a
+// *CircuitOpenError while a circuit is open. Install it with
+// WithCircuitBreaker or register Middleware directly.
+type CircuitBreaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	circuits map[BreakerKey]*circuit
+}
+
+type circuit struct {
+	state       BreakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	trial       bool // a half-open trial call is in flight
+}
+
+// NewCircuitBreaker constructs a CircuitBreaker.
+func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.FailureRatio <= 0 || cfg.FailureRatio > 1 {
+		cfg.FailureRatio = 0.5
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 5
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= 500
+		}
+	}
+	return &CircuitBreaker{cfg: cfg, now: time.Now, circuits: map[BreakerKey]*circuit{}}
+}
+
+// WithCircuitBreaker installs a circuit breaker built from cfg as middleware.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) ClientOption {
+	return WithMiddleware(NewCircuitBreaker(cfg).Middleware())
+}
+
+// State returns the current state of the circuit for host and model.
+func (b *CircuitBreaker) State(host, model string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cb := b.circuits[BreakerKey{Host: host, Model: model}]; cb != nil {
+		return cb.state
+	}
+	return BreakerClosed
+}
+
+// Middleware returns the breaker as client middleware.
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			key := BreakerKey{Host: req.Host, Model: req.Model}
+			if err := b.allow(key); err != nil {
+				return nil, err
+			}
+			resp, err := next(ctx, req)
+			if ctx.Err() != nil {
+				b.release(key)
+				return resp, err
+			}
+			b.record(key, b.cfg.IsFailure(resp, err))
+			return resp, err
+		}
+	}
+}
+
+// allow admits a call or returns a *CircuitOpenError.
+func (b *CircuitBreaker) allow(key BreakerKey) error {
+	b.mu.Lock()
+	cb := b.circuits[key]
+	if cb == nil {
+		cb = &circuit{windowStart: b.now()}
+		b.circuits[key] = cb
+	}
+	var from BreakerState
+	switch cb.state {
+	case BreakerOpen:
+		wait := b.cfg.Cooldown - b.now().Sub(cb.openedAt)
+		if wait > 0 {
+			b.mu.Unlock()
+			return &CircuitOpenError{Host: key.Host, Model: key.Model, RetryAfter: wait}
+		}
+		from = cb.state
+		cb.state = BreakerHalfOpen
+		cb.trial = true
+		b.mu.Unlock()
+		b.notify(key, from, BreakerHalfOpen)
+		return nil
+	case BreakerHalfOpen:
+		if cb.trial {
+			b.mu.Unlock()
+			return &CircuitOpenError{Host: key.Host, Model: key.Model}
+		}
+		cb.trial = true
+	}
+	b.mu.Unlock()
+	return nil
+}
+
+// release frees a half-open trial slot without recording an outcome.
+func (b *CircuitBreaker) release(key BreakerKey) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cb := b.circuits[key]; cb != nil {
+		cb.trial = false
+	}
+}
+
+// record accounts one completed call and performs any transition.
+func (b *CircuitBreaker) record(key BreakerKey, failed bool) {
+	b.mu.Lock()
+	cb := b.circuits[key]
+	from, to := cb.state, cb.state
+	now := b.now()
+	switch cb.state {
+	case BreakerHalfOpen:
+		cb.trial = false
+		if failed {
+			to = BreakerOpen
+			cb.openedAt = now
+		} else {
+			to = BreakerClosed
+			cb.windowStart, cb.requests, cb.failures = now, 0, 0
+		}
+	case BreakerClosed:
+		if now.Sub(cb.windowStart) >= b.cfg.Window {
+			cb.windowStart, cb.requests, cb.failures = now, 0, 0
+		}
+		cb.requests++
+		if failed {
+			cb.failures++
+		}
+		if cb.requests >= b.cfg.MinRequests && float64(cb.failures)/float64(cb.requests) >= b.cfg.FailureRatio {
+			to = BreakerOpen
+			cb.openedAt = now
+		}
+	}
+	cb.state = to
+	b.mu.Unlock()
+	if from != to {
+		b.notify(key, from, to)
+	}
+}
+
+func (b *CircuitBreaker) notify(key BreakerKey, from, to BreakerState) {
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(key, from, to)
+	}
+}
@@ -0,0 +1,125 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func chatModel(c *Client, model string) error {
+	_, err := c.Chat(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: model}})
+	return err
+}
+
+func TestCircuitBreaker_OpensHalfOpensAndCloses(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(500)
+			_, _ = io.WriteString(w, `{"error":"out of memory"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"message":{"role":"assistant","content":"ok"}}`)
+	})
+	defer srv.Close()
+
+	now := time.Unix(0, 0)
+	var transitions []string
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		MinRequests: 3,
+		Cooldown:    time.Minute,
+		OnStateChange: func(k BreakerKey, from, to BreakerState) {
+			transitions = append(transitions, fmt.Sprintf("%s:%s->%s", k.Model, from, to))
+		},
+	})
+	cb.now = func() time.Time { return now }
+	WithMiddleware(cb.Middleware())(c)
+
+	for i := 0; i < 3; i++ {
+		if err := chatModel(c, "big"); err == nil {
+			t.Fatal("expected server error")
+		}
+	}
+	if cb.State(srv.URL, "big") != BreakerOpen {
+		t.Fatalf("state=%s", cb.State(srv.URL, "big"))
+	}
+	err := chatModel(c, "big")
+	var coe *CircuitOpenError
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &coe) || coe.Model != "big" || coe.RetryAfter != time.Minute {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("open circuit reached server: calls=%d", calls.Load())
+	}
+
+	// other models on the same host are unaffected
+	healthy.Store(true)
+	if err := chatModel(c, "small"); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := chatModel(c, "big"); err != nil {
+		t.Fatalf("trial call: %v", err)
+	}
+	if cb.State(srv.URL, "big") != BreakerClosed {
+		t.Fatalf("state=%s", cb.State(srv.URL, "big"))
+	}
+	want := []string{"big:closed->open", "big:open->half-open", "big:half-open->closed"}
+	if fmt.Sprint(transitions) != fmt.Sprint(want) {
+		t.Fatalf("transitions=%v", transitions)
+	}
+}
+
+func TestCircuitBreaker_FailedTrialReopens(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(500) })
+	defer srv.Close()
+	now := time.Unix(0, 0)
+	cb := NewCircuitBreaker(CircuitBreakerConfig{MinRequests: 1, Cooldown: time.Second})
+	cb.now = func() time.Time { return now }
+	WithMiddleware(cb.Middleware())(c)
+
+	_ = chatModel(c, "m")
+	now = now.Add(time.Second)
+	if err := chatModel(c, "m"); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected trial to reach server: %v", err)
+	}
+	if err := chatModel(c, "m"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected reopened circuit: %v", err)
+	}
+}
+
+func TestCircuitBreaker_ClientErrorsDoNotTrip(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(404) })
+	defer srv.Close()
+	cb := NewCircuitBreaker(CircuitBreakerConfig{MinRequests: 1})
+	WithMiddleware(cb.Middleware())(c)
+	for i := 0; i < 3; i++ {
+		if err := chatModel(c, "m"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatal("404 should not open the circuit")
+		}
+	}
+}
+
+func TestCircuitBreaker_PoolSkipsOpenHost(t *testing.T) {
+	rt := newHostsTransport()
+	cb := NewCircuitBreaker(CircuitBreakerConfig{MinRequests: 1, Cooldown: time.Hour})
+	c := NewPool([]string{"a:1", "b:1"}, WithHTTPClient(&http.Client{Transport: rt}), WithMiddleware(cb.Middleware()))
+	defer func() { _ = c.Close() }()
+	// trip the circuit for host a directly
+	key := BreakerKey{Host: "http://a:1", Model: "m"}
+	_ = cb.allow(key)
+	cb.record(key, true)
+	for i := 0; i < 4; i++ {
+		if h := generateHost(t, c); h != "b" {
+			t.Fatalf("request %d went to %q", i, h)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CallOption customizes a single API call.
+type CallOption func(*callConfig)
+
+type callConfig struct {
+	header   http.Header
+	timeout  time.Duration
+	priority *Priority
+	idle     *[2]time.Duration
+}
+
+// WithCallHeader sets a header for this call only, overriding any client
+// default of the same name.
+func WithCallHeader(k, v string) CallOption {
+	return func(cc *callConfig) { cc.header.Set(k, v) }
+}
+
+// WithCallTimeout bounds this call. For streaming methods the timeout covers
+// the whole stream, until Close.
+func WithCallTimeout(d time.Duration) CallOption {
+	return func(cc *callConfig) { cc.timeout = d }
+}
+
+// WithRequestID sets the X-Request-ID header for this call.
+func WithRequestID(id string) CallOption { return WithCallHeader("X-Request-ID", id) }
+
+// applyCallOptions merges opts into req and returns the context to use for
+// the call. The returned cancel func must be called when the call, including
+// any stream, is finished.
+func applyCallOptions(ctx context.Context, req *Request, opts []CallOption) (context.Context, context.CancelFunc) {
+	if len(opts) == 0 {
+		return ctx, func() {}
+	}
+	cc := callConfig{header: http.Header{}}
+	for _, o := range opts {
+		o(&cc)
+	}
+	if len(cc.header) > 0 {
+		if req.Header == nil {
+			req.Header = http.Header{}
+		}
+		for k, vv := range cc.header {
+			req.Header[k] = vv
+		}
+	}
+	if cc.priority != nil {
+		req.priority = cc.priority
+	}
+	if cc.idle != nil {
+		req.idle = cc.idle
+	}
+	if cc.timeout > 0 {
+		return context.WithTimeout(ctx, cc.timeout)
+	}
+	return ctx, func() {}
+}
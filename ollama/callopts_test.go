@@ -0,0 +1,83 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCallOptions_HeadersAndRequestID(t *testing.T) {
+	var got http.Header
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = io.WriteString(w, `{"embeddings":[[1]]}`)
+	})
+	defer srv.Close()
+	WithHeader("X-Tenant", "default")(c)
+
+	_, err := c.Embed(context.Background(), &EmbedRequest{Model: "m", Input: "x"},
+		WithCallHeader("X-Tenant", "acme"), WithCallHeader("Traceparent", "00-abc-def-01"), WithRequestID("req-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("X-Tenant") != "acme" || len(got.Values("X-Tenant")) != 1 {
+		t.Fatalf("tenant header: %v", got.Values("X-Tenant"))
+	}
+	if got.Get("Traceparent") != "00-abc-def-01" || got.Get("X-Request-ID") != "req-1" {
+		t.Fatalf("headers: %v", got)
+	}
+	if got.Get("User-Agent") == "" {
+		t.Fatal("client defaults lost")
+	}
+
+	// per-call headers do not leak into later calls
+	if _, err := c.List(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("X-Tenant") != "default" || got.Get("X-Request-ID") != "" {
+		t.Fatalf("headers leaked: %v", got)
+	}
+}
+
+func TestCallOptions_Timeout(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	defer srv.Close()
+	start := time.Now()
+	_, err := c.Show(context.Background(), "m", WithCallTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("timeout not applied: %s", time.Since(start))
+	}
+}
+
+func TestCallOptions_StreamTimeoutCoversBody(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = io.WriteString(w, "{\"response\":\"a\"}\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}, WithCallTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	if _, err := s.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Recv(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
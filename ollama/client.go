@@ -3,17 +3,20 @@ package ollama
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
-	"net/url"
 	"os"
 	"runtime"
 	"strings"
 	"syscall"
+	"time"
 )
 
 const defaultBase = "http://127.0.0.1:11434"
@@ -24,23 +27,68 @@ type Client struct {
 	hc     *http.Client
 	base   string
 	header http.Header
+	retry  *RetryPolicy
+	tokens TokenSource
+
+	signer    crypto.Signer
+	signPaths []string
+
+	// transport customization applied after options
+	socket  string
+	dial    DialFunc
+	tlsOpts tlsOptions
+	err     error // configuration error returned from every call
+
+	middleware []Middleware
+	tracer     Tracer
+	logger     *slog.Logger
+	metrics    *Metrics
+	autoPull   func(model string, p *ProgressResponse)
+	pulls      *pullGroup
+
+	// stream idle timeouts; see WithStreamIdleTimeout
+	firstChunkTimeout time.Duration
+	chunkTimeout      time.Duration
+	maxLine           int // see WithMaxStreamLine; 0 means the default
+
+	// multi-host state; pool is nil for a single host
+	pool           *hostPool
+	balance        BalanceStrategy
+	healthInterval time.Duration
+	affinityTTL    time.Duration
 }
 
 // NewClient constructs a Client. If host is empty, it uses the OLLAMA_HOST
 // environment variable; if that too is empty, it defaults to 127.0.0.1:11434.
+// A comma-separated host list yields a multi-host client (see NewPool), and
+// unix:///path/to/ollama.sock or http+unix://%2Fpath%2Fto%2Follama.sock
+// connects over a unix domain socket. OLLAMA_API_KEY, when set, is sent as a
+// bearer token unless WithAPIKey or WithTokenSource is given.
 func NewClient(host string, opts ...ClientOption) *Client {
 	base := host
 	if base == "" {
 		base = os.Getenv("OLLAMA_HOST")
 	}
-	if base != "" {
+	var hosts []string
+	socket, prefix, isUnix := parseUnixHost(base)
+	if !isUnix && strings.Contains(base, ",") {
+		hosts = splitHosts(base)
+	}
+	switch {
+	case isUnix:
+		base = unixBase + prefix
+	case len(hosts) > 0:
+		base = hosts[0]
+	case base != "":
 		base = parseHost(base)
-	} else {
+	default:
 		base = defaultBase
 	}
 	c := &Client{
-		hc:   &http.Client{},
-		base: base,
+		hc:     &http.Client{},
+		base:   base,
+		socket: socket,
+		pulls:  &pullGroup{},
 		header: http.Header{
 			"Content-Type": []string{"application/json"},
 			"Accept":       []string{"application/json"},
@@ -50,6 +98,18 @@ func NewClient(host string, opts ...ClientOption) *Client {
 	for _, o := range opts {
 		o(c)
 	}
+	if c.tokens == nil {
+		if key := os.Getenv("OLLAMA_API_KEY"); key != "" {
+			c.tokens = StaticTokenSource(key)
+		}
+	}
+	c.configureTransport()
+	if len(hosts) > 1 {
+		c.pool = newHostPool(c, hosts)
+		if c.affinityTTL > 0 {
+			c.pool.affinity = newAffinityRouter(c, c.affinityTTL)
+		}
+	}
 	return c
 }
 
@@ -68,8 +128,106 @@ func WithHTTPClient(hc *http.Client) ClientOption {
 // WithHeader sets a default header value for all requests.
 func WithHeader(k, v string) ClientOption { return func(c *Client) { c.header.Set(k, v) } }
 
-func (c *Client) do(ctx context.Context, method, path string, body io.Reader, headers http.Header) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+func (c *Client) do(ctx context.Context, req *Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	ctx, tr := c.startTrace(ctx, req)
+	req.trace = tr
+	resp, err := c.attempt(ctx, req)
+	if err != nil && c.autoPull != nil && autoPullPaths[req.Path] && errors.Is(err, ErrModelNotFound) && rewind(req.Body) {
+		pc := c
+		if c.pool != nil && req.host != "" {
+			// pull to and retry on the host that lacks the model
+			pc = c.hostClient(req.host)
+		}
+		if perr := pc.pullShared(ctx, req.host, req.Model, autoPullSub{}, c.autoPull); perr != nil {
+			err = fmt.Errorf("ollama: auto-pull %s: %w", req.Model, perr)
+		} else {
+			resp, err = pc.attempt(ctx, req)
+		}
+	}
+	if err != nil {
+		tr.end(err)
+		return nil, err
+	}
+	if tr != nil {
+		tr.opened()
+		resp.Body = &tracedBody{ReadCloser: resp.Body, t: tr}
+	}
+	return resp, nil
+}
+
+// attempt sends req, retrying per the client's RetryPolicy, and maps
+// connection failures and error statuses to the package's error types.
+func (c *Client) attempt(ctx context.Context, req *Request) (*http.Response, error) {
+	attempts := 1
+	if c.retry != nil {
+		attempts = c.retry.MaxAttempts
+	}
+	rt := c.chain()
+	for attempt := 1; ; attempt++ {
+		resp, err := c.roundTrip(ctx, rt, req)
+		req.stall.pause()
+		req.trace.attempt(resp)
+		if attempt < attempts && ctx.Err() == nil && (err != nil || resp.StatusCode >= 400) && c.retry.retryable(req.Method, resp, err) {
+			delay := c.retry.backoff(attempt, resp)
+			if fitsDeadline(ctx, delay) && rewind(req.Body) {
+				ev := RetryEvent{Method: req.Method, Path: req.Path, Attempt: attempt, Err: err, Delay: delay}
+				if resp != nil {
+					ev.StatusCode = resp.StatusCode
+					_, _ = io.Copy(io.Discard, resp.Body)
+					_ = resp.Body.Close()
+				}
+				req.trace.retry(ev)
+				if c.retry.OnRetry != nil {
+					c.retry.OnRetry(ev)
+				}
+				if err := sleepCtx(ctx, delay); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		if err != nil {
+			if isConnectErr(err) || isTimeout(err) && ctx.Err() == nil {
+				return nil, &ConnectionError{Message: connectionErrorMessage, Cause: err}
+			}
+			return nil, err
+		}
+		if resp.StatusCode >= 400 {
+			b, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			re := newResponseError(resp.StatusCode, b).(*ResponseError)
+			re.Header = resp.Header
+			return nil, re
+		}
+		return resp, nil
+	}
+}
+
+// send performs a single attempt without mapping errors or status codes. It
+// is the innermost RoundTripFunc of the middleware chain.
+func (c *Client) send(ctx context.Context, r *Request) (*http.Response, error) {
+	var body io.Reader
+	var raw []byte
+	switch v := r.Body.(type) {
+	case nil:
+	case io.Reader:
+		body = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+		raw = b
+	}
+	base := r.Host
+	if base == "" {
+		base = c.base
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method, base+r.Path, body)
 	if err != nil {
 		return nil, err
 	}
@@ -79,24 +237,15 @@ func (c *Client) do(ctx context.Context, method, path string, body io.Reader, he
 			req.Header.Add(k, v)
 		}
 	}
-	for k, vv := range headers {
+	for k, vv := range r.Header {
+		req.Header.Del(k)
 		for _, v := range vv {
 			req.Header.Add(k, v)
 		}
 	}
-	resp, err := c.hc.Do(req)
-	if err != nil {
-		if isConnectErr(err) {
-			return nil, &ConnectionError{Message: connectionErrorMessage}
-		}
-		return nil, err
-	}
-	if resp.StatusCode >= 400 {
-		b, _ := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
-		return nil, newResponseError(resp.StatusCode, b)
-	}
-	return resp, nil
+	c.logRequest(ctx, r, req, raw)
+	r.stall.arm()
+	return c.hc.Do(req)
 }
 
 func isConnectErr(err error) bool {
@@ -104,16 +253,14 @@ func isConnectErr(err error) bool {
 	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTUNREACH) {
 		return true
 	}
-	// url.Error wrapping net errors
-	var ue *url.Error
-	if errors.As(err, &ue) {
-		if errors.Is(ue.Err, syscall.ECONNREFUSED) || errors.Is(ue.Err, syscall.ENETUNREACH) || errors.Is(ue.Err, syscall.EHOSTUNREACH) {
-			return true
-		}
-		// last resort substring check
-		if strings.Contains(strings.ToLower(ue.Err.Error()), "connection refused") {
-			return true
-		}
+	// name resolution and dial failures happen before anything is sent
+	var dns *net.DNSError
+	if errors.As(err, &dns) {
+		return true
+	}
+	var op *net.OpError
+	if errors.As(err, &op) && op.Op == "dial" {
+		return true
 	}
 	// last resort substring on raw error
 	if strings.Contains(strings.ToLower(err.Error()), "connection refused") {
@@ -122,181 +269,183 @@ func isConnectErr(err error) bool {
 	return false
 }
 
+// isTimeout reports a network timeout, such as http.Client.Timeout or a
+// transport header timeout, that was not caused by the caller's context.
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
 // requestJSON sends JSON and decodes JSON.
 // It avoids HTML-escaping to match Python client's encoding behavior.
-func requestJSON[Req any, Res any](ctx context.Context, c *Client, method, path string, in *Req) (*Res, error) {
-    var body io.Reader
-    if in != nil {
-        b, err := json.Marshal(in)
-        if err != nil {
-            return nil, err
-        }
-        body = bytes.NewReader(b)
-    }
-    resp, err := c.do(ctx, method, path, body, nil)
-    if err != nil {
-        return nil, err
-    }
-    defer func() { _ = resp.Body.Close() }()
-    var out Res
-    dec := json.NewDecoder(resp.Body)
-    if err := dec.Decode(&out); err != nil {
-        return nil, err
-    }
-    return &out, nil
+func requestJSON[Res any](ctx context.Context, c *Client, req *Request, opts []CallOption) (*Res, error) {
+	ctx, cancel := applyCallOptions(ctx, req, opts)
+	defer cancel()
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var out Res
+	dec := json.NewDecoder(resp.Body)
+	if err := dec.Decode(&out); err != nil {
+		req.trace.fail(err)
+		return nil, err
+	}
+	req.trace.response(&out)
+	return &out, nil
+}
+
+// openStream sends a streaming request and wraps the response in a Stream.
+func openStream[T any](ctx context.Context, c *Client, req *Request, opts []CallOption) (*Stream[T], error) {
+	req.Stream = true
+	ctx, cancel := applyCallOptions(ctx, req, opts)
+	first, between := c.firstChunkTimeout, c.chunkTimeout
+	if req.idle != nil {
+		first, between = req.idle[0], req.idle[1]
+	}
+	ctx, stall := startStallTimer(ctx, first, between)
+	req.stall = stall
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		stall.stop()
+		cancel()
+		if serr := stalled(ctx); serr != nil {
+			return nil, serr
+		}
+		return nil, err
+	}
+	if stall != nil {
+		resp.Body = &stallBody{ReadCloser: resp.Body, t: stall}
+	}
+	s := newStream[T](ctx, resp)
+	s.cancel = func() {
+		stall.stop()
+		cancel()
+	}
+	s.trace = req.trace
+	if c.maxLine > 0 {
+		s.maxLine = c.maxLine
+	}
+	return s, nil
 }
 
 // Generate
 // Generate performs a non-streaming generation request.
-func (c *Client) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+func (c *Client) Generate(ctx context.Context, req *GenerateRequest, opts ...CallOption) (*GenerateResponse, error) {
 	if err := ensureModel(req.BaseStreamableRequest.Model); err != nil {
 		return nil, err
 	}
-	return requestJSON[GenerateRequest, GenerateResponse](ctx, c, http.MethodPost, "/api/generate", req)
+	return requestJSON[GenerateResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/generate", Model: req.Model, Body: req}, opts)
 }
 
 // GenerateStream performs a streaming generation request and returns a Stream.
-func (c *Client) GenerateStream(ctx context.Context, req *GenerateRequest) (*Stream[GenerateResponse], error) {
+func (c *Client) GenerateStream(ctx context.Context, req *GenerateRequest, opts ...CallOption) (*Stream[GenerateResponse], error) {
 	if err := ensureModel(req.BaseStreamableRequest.Model); err != nil {
 		return nil, err
 	}
-    s := true
-    req.Stream = &s
-    b, err := json.Marshal(req)
-    if err != nil { return nil, err }
-    resp, err := c.do(ctx, http.MethodPost, "/api/generate", bytes.NewReader(b), nil)
-    if err != nil {
-        return nil, err
-    }
-    return newStream[GenerateResponse](ctx, resp), nil
+	req.Stream = BoolPtr(true)
+	return openStream[GenerateResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/generate", Model: req.Model, Body: req}, opts)
 }
 
 // Chat
 // Chat performs a non-streaming chat request.
-func (c *Client) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+func (c *Client) Chat(ctx context.Context, req *ChatRequest, opts ...CallOption) (*ChatResponse, error) {
 	if err := ensureModel(req.BaseStreamableRequest.Model); err != nil {
 		return nil, err
 	}
-	return requestJSON[ChatRequest, ChatResponse](ctx, c, http.MethodPost, "/api/chat", req)
+	return requestJSON[ChatResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/chat", Model: req.Model, Body: req}, opts)
 }
 
 // ChatStream performs a streaming chat request and returns a Stream.
-func (c *Client) ChatStream(ctx context.Context, req *ChatRequest) (*Stream[ChatResponse], error) {
+func (c *Client) ChatStream(ctx context.Context, req *ChatRequest, opts ...CallOption) (*Stream[ChatResponse], error) {
 	if err := ensureModel(req.BaseStreamableRequest.Model); err != nil {
 		return nil, err
 	}
-    s := true
-    req.Stream = &s
-    b, err := json.Marshal(req)
-    if err != nil { return nil, err }
-    resp, err := c.do(ctx, http.MethodPost, "/api/chat", bytes.NewReader(b), nil)
-    if err != nil {
-        return nil, err
-    }
-    return newStream[ChatResponse](ctx, resp), nil
+	req.Stream = BoolPtr(true)
+	return openStream[ChatResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/chat", Model: req.Model, Body: req}, opts)
 }
 
 // Embed
 // Embed requests embeddings from models that support /api/embed.
-func (c *Client) Embed(ctx context.Context, req *EmbedRequest) (*EmbedResponse, error) {
+func (c *Client) Embed(ctx context.Context, req *EmbedRequest, opts ...CallOption) (*EmbedResponse, error) {
 	if err := ensureModel(req.Model); err != nil {
 		return nil, err
 	}
-	return requestJSON[EmbedRequest, EmbedResponse](ctx, c, http.MethodPost, "/api/embed", req)
+	return requestJSON[EmbedResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/embed", Model: req.Model, Body: req}, opts)
 }
 
 // Embeddings (deprecated)
 // Embeddings requests embeddings via the deprecated /api/embeddings endpoint.
-func (c *Client) Embeddings(ctx context.Context, req *EmbeddingsRequest) (*EmbeddingsResponse, error) {
+func (c *Client) Embeddings(ctx context.Context, req *EmbeddingsRequest, opts ...CallOption) (*EmbeddingsResponse, error) {
 	if err := ensureModel(req.Model); err != nil {
 		return nil, err
 	}
-	return requestJSON[EmbeddingsRequest, EmbeddingsResponse](ctx, c, http.MethodPost, "/api/embeddings", req)
+	return requestJSON[EmbeddingsResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/embeddings", Model: req.Model, Body: req}, opts)
 }
 
 // Pull
 // Pull pulls a model; returns a final progress snapshot.
-func (c *Client) Pull(ctx context.Context, req *PullRequest) (*ProgressResponse, error) {
+func (c *Client) Pull(ctx context.Context, req *PullRequest, opts ...CallOption) (*ProgressResponse, error) {
 	if err := ensureModel(req.Model); err != nil {
 		return nil, err
 	}
-	return requestJSON[PullRequest, ProgressResponse](ctx, c, http.MethodPost, "/api/pull", req)
+	return requestJSON[ProgressResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/pull", Model: req.Model, Body: req}, opts)
 }
 
 // PullStream pulls a model and returns a progress stream.
-func (c *Client) PullStream(ctx context.Context, req *PullRequest) (*Stream[ProgressResponse], error) {
+func (c *Client) PullStream(ctx context.Context, req *PullRequest, opts ...CallOption) (*Stream[ProgressResponse], error) {
 	if err := ensureModel(req.Model); err != nil {
 		return nil, err
 	}
-    s := true
-    req.Stream = &s
-    b, err := json.Marshal(req)
-    if err != nil { return nil, err }
-    resp, err := c.do(ctx, http.MethodPost, "/api/pull", bytes.NewReader(b), nil)
-	if err != nil {
-		return nil, err
-	}
-	return newStream[ProgressResponse](ctx, resp), nil
+	req.Stream = BoolPtr(true)
+	return openStream[ProgressResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/pull", Model: req.Model, Body: req}, opts)
 }
 
 // Push
 // Push pushes a model; returns a final progress snapshot.
-func (c *Client) Push(ctx context.Context, req *PushRequest) (*ProgressResponse, error) {
+func (c *Client) Push(ctx context.Context, req *PushRequest, opts ...CallOption) (*ProgressResponse, error) {
 	if err := ensureModel(req.Model); err != nil {
 		return nil, err
 	}
-	return requestJSON[PushRequest, ProgressResponse](ctx, c, http.MethodPost, "/api/push", req)
+	return requestJSON[ProgressResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/push", Model: req.Model, Body: req}, opts)
 }
 
 // PushStream pushes a model and returns a progress stream.
-func (c *Client) PushStream(ctx context.Context, req *PushRequest) (*Stream[ProgressResponse], error) {
+func (c *Client) PushStream(ctx context.Context, req *PushRequest, opts ...CallOption) (*Stream[ProgressResponse], error) {
 	if err := ensureModel(req.Model); err != nil {
 		return nil, err
 	}
-    s := true
-    req.Stream = &s
-    b, err := json.Marshal(req)
-    if err != nil { return nil, err }
-    resp, err := c.do(ctx, http.MethodPost, "/api/push", bytes.NewReader(b), nil)
-	if err != nil {
-		return nil, err
-	}
-	return newStream[ProgressResponse](ctx, resp), nil
+	req.Stream = BoolPtr(true)
+	return openStream[ProgressResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/push", Model: req.Model, Body: req}, opts)
 }
 
 // Create
 // Create creates a model; returns a final progress snapshot.
-func (c *Client) Create(ctx context.Context, req *CreateRequest) (*ProgressResponse, error) {
+func (c *Client) Create(ctx context.Context, req *CreateRequest, opts ...CallOption) (*ProgressResponse, error) {
 	if err := ensureModel(req.Model); err != nil {
 		return nil, err
 	}
-	return requestJSON[CreateRequest, ProgressResponse](ctx, c, http.MethodPost, "/api/create", req)
+	return requestJSON[ProgressResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/create", Model: req.Model, Body: req}, opts)
 }
 
 // CreateStream creates a model and returns a progress stream.
-func (c *Client) CreateStream(ctx context.Context, req *CreateRequest) (*Stream[ProgressResponse], error) {
+func (c *Client) CreateStream(ctx context.Context, req *CreateRequest, opts ...CallOption) (*Stream[ProgressResponse], error) {
 	if err := ensureModel(req.Model); err != nil {
 		return nil, err
 	}
-    s := true
-    req.Stream = &s
-    b, err := json.Marshal(req)
-    if err != nil { return nil, err }
-    resp, err := c.do(ctx, http.MethodPost, "/api/create", bytes.NewReader(b), nil)
-	if err != nil {
-		return nil, err
-	}
-	return newStream[ProgressResponse](ctx, resp), nil
+	req.Stream = BoolPtr(true)
+	return openStream[ProgressResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/create", Model: req.Model, Body: req}, opts)
 }
 
 // Blobs
 // CreateBlob uploads a blob content-addressed by sha256:hex digest.
-func (c *Client) CreateBlob(ctx context.Context, path string) (string, error) {
+func (c *Client) CreateBlob(ctx context.Context, path string, opts ...CallOption) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return "", err
 	}
-    defer func(){ _ = f.Close() }()
+	defer func() { _ = f.Close() }()
 	h := sha256.New()
 	if _, err := io.Copy(h, f); err != nil {
 		return "", err
@@ -306,23 +455,29 @@ func (c *Client) CreateBlob(ctx context.Context, path string) (string, error) {
 	if _, err := f.Seek(0, 0); err != nil {
 		return "", err
 	}
-	resp, err := c.do(ctx, http.MethodPost, "/api/blobs/"+digest, f, http.Header{})
+	req := &Request{Method: http.MethodPost, Path: "/api/blobs/" + digest, Body: f}
+	ctx, cancel := applyCallOptions(ctx, req, opts)
+	defer cancel()
+	resp, err := c.do(ctx, req)
 	if err != nil {
 		return "", err
 	}
-    _ = resp.Body.Close()
+	_ = resp.Body.Close()
 	return digest, nil
 }
 
 // List models
 // List returns installed model tags.
-func (c *Client) List(ctx context.Context) (*ListResponse, error) {
-	return requestJSON[struct{}, ListResponse](ctx, c, http.MethodGet, "/api/tags", nil)
+func (c *Client) List(ctx context.Context, opts ...CallOption) (*ListResponse, error) {
+	return requestJSON[ListResponse](ctx, c, &Request{Method: http.MethodGet, Path: "/api/tags"}, opts)
 }
 
 // Delete removes a model by name and returns a Python-parity status.
-func (c *Client) Delete(ctx context.Context, model string) (*StatusResponse, error) {
-	resp, err := c.do(ctx, http.MethodDelete, "/api/delete", jsonBody(DeleteRequest{Model: model}), nil)
+func (c *Client) Delete(ctx context.Context, model string, opts ...CallOption) (*StatusResponse, error) {
+	req := &Request{Method: http.MethodDelete, Path: "/api/delete", Model: model, Body: &DeleteRequest{Model: model}}
+	ctx, cancel := applyCallOptions(ctx, req, opts)
+	defer cancel()
+	resp, err := c.do(ctx, req)
 	if err != nil {
 		// newResponseError already returned error; but we need status mapping like Python
 		return &StatusResponse{Status: StrPtr("error")}, nil
@@ -331,14 +486,17 @@ func (c *Client) Delete(ctx context.Context, model string) (*StatusResponse, err
 	if resp.StatusCode == http.StatusOK {
 		st = "success"
 	}
-    _, _ = io.Copy(io.Discard, resp.Body)
-    _ = resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
 	return &StatusResponse{Status: StrPtr(st)}, nil
 }
 
 // Copy duplicates a model.
-func (c *Client) Copy(ctx context.Context, source, destination string) (*StatusResponse, error) {
-	resp, err := c.do(ctx, http.MethodPost, "/api/copy", jsonBody(CopyRequest{Source: source, Destination: destination}), nil)
+func (c *Client) Copy(ctx context.Context, source, destination string, opts ...CallOption) (*StatusResponse, error) {
+	req := &Request{Method: http.MethodPost, Path: "/api/copy", Model: source, Body: &CopyRequest{Source: source, Destination: destination}}
+	ctx, cancel := applyCallOptions(ctx, req, opts)
+	defer cancel()
+	resp, err := c.do(ctx, req)
 	if err != nil {
 		return &StatusResponse{Status: StrPtr("error")}, nil
 	}
@@ -346,19 +504,19 @@ func (c *Client) Copy(ctx context.Context, source, destination string) (*StatusR
 	if resp.StatusCode == http.StatusOK {
 		st = "success"
 	}
-    _, _ = io.Copy(io.Discard, resp.Body)
-    _ = resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
 	return &StatusResponse{Status: StrPtr(st)}, nil
 }
 
 // Show returns model information for a given tag.
-func (c *Client) Show(ctx context.Context, model string) (*ShowResponse, error) {
-	return requestJSON[ShowRequest, ShowResponse](ctx, c, http.MethodPost, "/api/show", &ShowRequest{Model: model})
+func (c *Client) Show(ctx context.Context, model string, opts ...CallOption) (*ShowResponse, error) {
+	return requestJSON[ShowResponse](ctx, c, &Request{Method: http.MethodPost, Path: "/api/show", Model: model, Body: &ShowRequest{Model: model}}, opts)
 }
 
 // PS lists running models/processes.
-func (c *Client) PS(ctx context.Context) (*ProcessResponse, error) {
-	return requestJSON[struct{}, ProcessResponse](ctx, c, http.MethodGet, "/api/ps", nil)
+func (c *Client) PS(ctx context.Context, opts ...CallOption) (*ProcessResponse, error) {
+	return requestJSON[ProcessResponse](ctx, c, &Request{Method: http.MethodGet, Path: "/api/ps"}, opts)
 }
 
 // helpers
@@ -370,7 +528,14 @@ func ensureModel(m string) error {
 	return nil
 }
 
-func jsonBody(v any) io.Reader {
-    b, _ := json.Marshal(v)
-    return bytes.NewReader(b)
+// encodeJSON marshals v without HTML-escaping and without a trailing newline,
+// for logs and SSE events. Request bodies use json.Marshal.
+func encodeJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
 }
@@ -214,3 +214,21 @@ func TestCreateBlob(t *testing.T) {
 		t.Fatalf("bad digest: %s", digest)
 	}
 }
+
+func TestRequestBody_HTMLEscaped(t *testing.T) {
+	var got string
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+		_, _ = io.WriteString(w, `{"response":"ok","done":true}`)
+	})
+	defer srv.Close()
+	prompt := "<b>&</b>"
+	if _, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}, Prompt: &prompt}); err != nil {
+		t.Fatal(err)
+	}
+	// encoding/json's default escaping, as sent before middleware existed
+	if !strings.Contains(got, `"prompt":"\u003cb\u003e\u0026\u003c/b\u003e"`) {
+		t.Fatalf("body=%s", got)
+	}
+}
@@ -0,0 +1,74 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// unixBase is the URL used for requests sent over a unix socket. The host is
+// never resolved; it only fills the Host header.
+const unixBase = "http://localhost"
+
+// DialFunc opens a connection, like net.Dialer.DialContext.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WithDialer sets the function used to open connections. For unix socket
+// hosts it is called with network "unix" and the socket path.
+func WithDialer(dial DialFunc) ClientOption {
+	return func(c *Client) { c.dial = dial }
+}
+
+// configureTransport applies the custom dialer, unix socket and TLS settings,
+// if any, to a clone of the client's transport. A custom http.RoundTripper
+// supplied via WithHTTPClient is left untouched, so a dialer, unix socket or
+// TLS options cannot be installed on it; that, like an error loading TLS
+// material, is kept and returned from every call rather than sending requests
+// elsewhere or without the requested TLS settings. TLS settings taken only
+// from the environment are ignored for custom transports.
+func (c *Client) configureTransport() {
+	tlsCfg, err := c.tlsOpts.build()
+	if err != nil {
+		c.err = err
+		return
+	}
+	if c.dial == nil && c.socket == "" && tlsCfg == nil {
+		return
+	}
+	var tr *http.Transport
+	switch t := c.hc.Transport.(type) {
+	case nil:
+		tr = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		tr = t.Clone()
+	default:
+		switch {
+		case c.dial != nil || c.socket != "":
+			c.err = fmt.Errorf("ollama: cannot use a unix socket or WithDialer with custom transport %T; configure dialing on it instead", t)
+		case tlsCfg != nil && c.tlsOpts.explicit():
+			c.err = fmt.Errorf("ollama: cannot apply TLS options to custom transport %T; configure TLS on it instead", t)
+		}
+		return
+	}
+	if tlsCfg != nil {
+		tr.TLSClientConfig = tlsCfg
+	}
+	if c.dial != nil || c.socket != "" {
+		dial := c.dial
+		if dial == nil {
+			var d net.Dialer
+			dial = d.DialContext
+		}
+		if sock := c.socket; sock != "" {
+			inner := dial
+			dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return inner(ctx, "unix", sock)
+			}
+		}
+		tr.DialContext = dial
+	}
+	hc := *c.hc
+	hc.Transport = tr
+	c.hc = &hc
+}
@@ -0,0 +1,128 @@
+package ollama
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// serveUnix serves handler on a fresh unix socket and returns its path.
+func serveUnix(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ollama")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	sock := filepath.Join(dir, "ollama.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv := &http.Server{Handler: handler}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return sock
+}
+
+func TestUnixSocket_Host(t *testing.T) {
+	var gotPath string
+	sock := serveUnix(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = io.WriteString(w, `{"models":[]}`)
+	})
+	c := NewClient("unix://" + sock)
+	if _, err := c.List(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/api/tags" {
+		t.Fatalf("path=%q", gotPath)
+	}
+}
+
+func TestUnixSocket_HTTPUnixWithPrefix(t *testing.T) {
+	var gotPath string
+	sock := serveUnix(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = io.WriteString(w, `{"models":[]}`)
+	})
+	old := os.Getenv("OLLAMA_HOST")
+	defer func() { _ = os.Setenv("OLLAMA_HOST", old) }()
+	_ = os.Setenv("OLLAMA_HOST", "http+unix://"+url.PathEscape(sock)+"/proxy/")
+	c := NewClient("")
+	if _, err := c.PS(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/proxy/api/ps" {
+		t.Fatalf("path=%q", gotPath)
+	}
+}
+
+func TestWithDialer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"models":[]}`)
+	}))
+	defer srv.Close()
+	target := strings.TrimPrefix(srv.URL, "http://")
+	var dials atomic.Int32
+	var d net.Dialer
+	c := NewClient("ollama.internal:11434", WithDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		if addr != "ollama.internal:11434" {
+			t.Errorf("addr=%q", addr)
+		}
+		return d.DialContext(ctx, network, target)
+	}))
+	if _, err := c.List(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if dials.Load() == 0 {
+		t.Fatal("dialer not used")
+	}
+}
+
+func TestParseUnixHost(t *testing.T) {
+	cases := []struct {
+		in, sock, prefix string
+		ok               bool
+	}{
+		{"unix:///var/run/ollama.sock", "/var/run/ollama.sock", "", true},
+		{"http+unix://%2Fvar%2Frun%2Follama.sock", "/var/run/ollama.sock", "", true},
+		{"http+unix://%2Fvar%2Frun%2Follama.sock/api/", "/var/run/ollama.sock", "/api", true},
+		{"unix://", "", "", false},
+		{"http://localhost", "", "", false},
+	}
+	for _, tc := range cases {
+		sock, prefix, ok := parseUnixHost(tc.in)
+		if sock != tc.sock || prefix != tc.prefix || ok != tc.ok {
+			t.Errorf("parseUnixHost(%q) = %q, %q, %v", tc.in, sock, prefix, ok)
+		}
+	}
+}
+
+func TestUnixSocket_CustomTransportFails(t *testing.T) {
+	var sent atomic.Int32
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		sent.Add(1)
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"models":[]}`)), Request: r}, nil
+	})
+	for name, c := range map[string]*Client{
+		"socket": NewClient("unix:///tmp/ollama.sock", WithHTTPClient(&http.Client{Transport: rt})),
+		"dialer": NewClient("", WithHTTPClient(&http.Client{Transport: rt}), WithDialer((&net.Dialer{}).DialContext)),
+	} {
+		if _, err := c.List(context.Background()); err == nil || !strings.Contains(err.Error(), "custom transport") {
+			t.Errorf("%s: unexpected err: %v", name, err)
+		}
+	}
+	if sent.Load() != 0 {
+		t.Fatalf("%d requests sent through the custom transport", sent.Load())
+	}
+}
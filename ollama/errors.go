@@ -2,7 +2,31 @@ package ollama
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched with errors.Is against a *ResponseError, including
+// errors reported in the middle of a stream.
+var (
+	// ErrModelNotFound: the model is not installed; pull it first.
+	ErrModelNotFound = errors.New("ollama: model not found")
+	// ErrContextTooLong: the input exceeds the model's context length.
+	ErrContextTooLong = errors.New("ollama: input exceeds context length")
+	// ErrModelDoesNotSupportTools: tools were sent to a model without tool
+	// calling.
+	ErrModelDoesNotSupportTools = errors.New("ollama: model does not support tools")
+	// ErrModelDoesNotSupportThinking: think was set for a model without a
+	// thinking mode.
+	ErrModelDoesNotSupportThinking = errors.New("ollama: model does not support thinking")
+	// ErrUnauthorized: the server rejected the credentials (401 or 403).
+	ErrUnauthorized = errors.New("ollama: unauthorized")
+	// ErrServerOverloaded: the server is busy or rate limiting (429 or 503).
+	ErrServerOverloaded = errors.New("ollama: server overloaded")
+	// ErrServerError: any other 5xx response.
+	ErrServerError = errors.New("ollama: server error")
 )
 
 // RequestError mirrors the Python client RequestError: client-side validation
@@ -16,6 +40,10 @@ func (e *RequestError) Error() string { return e.Message }
 type ResponseError struct {
 	Message    string
 	StatusCode int
+	// Body is the raw response body and Header the response headers; both
+	// are empty for errors reported inside a stream.
+	Body   []byte
+	Header http.Header
 }
 
 func (e *ResponseError) Error() string {
@@ -25,21 +53,59 @@ func (e *ResponseError) Error() string {
 	return e.Message
 }
 
+// Is classifies the error by status code and server message, so callers can
+// test errors.Is(err, ErrModelNotFound) instead of matching strings.
+func (e *ResponseError) Is(target error) bool {
+	msg := strings.ToLower(e.Message)
+	switch target {
+	case ErrModelNotFound:
+		// not a bare 404: older servers answer unknown endpoints with
+		// "404 page not found"
+		return strings.Contains(msg, "not found") && strings.Contains(msg, "model")
+	case ErrContextTooLong:
+		return strings.Contains(msg, "context length") || strings.Contains(msg, "context window") || strings.Contains(msg, "prompt too long")
+	case ErrModelDoesNotSupportTools:
+		return strings.Contains(msg, "does not support tools")
+	case ErrModelDoesNotSupportThinking:
+		return strings.Contains(msg, "does not support thinking")
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden || strings.Contains(msg, "unauthorized")
+	case ErrServerOverloaded:
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable || strings.Contains(msg, "server busy")
+	case ErrServerError:
+		return e.StatusCode >= 500 && e.StatusCode != http.StatusServiceUnavailable
+	}
+	return false
+}
+
 // newResponseError tries to read `{ "error": "..." }` else uses raw body.
 func newResponseError(status int, body []byte) error {
 	var tmp struct {
 		Error string `json:"error"`
 	}
 	if err := json.Unmarshal(body, &tmp); err == nil && tmp.Error != "" {
-		return &ResponseError{Message: tmp.Error, StatusCode: status}
+		return &ResponseError{Message: tmp.Error, StatusCode: status, Body: body}
 	}
-	return &ResponseError{Message: string(body), StatusCode: status}
+	return &ResponseError{Message: string(body), StatusCode: status, Body: body}
 }
 
 // ConnectionError mirrors Python's friendly connect error message for failed
-// connections to the Ollama server.
-type ConnectionError struct{ Message string }
+// connections to the Ollama server. Cause holds the underlying network error
+// (refused, unreachable, DNS or timeout) and is reachable with errors.Is and
+// errors.As.
+type ConnectionError struct {
+	Message string
+	Cause   error
+}
 
 func (e *ConnectionError) Error() string { return e.Message }
 
+func (e *ConnectionError) Unwrap() error { return e.Cause }
+
+// Timeout reports whether the connection failed because of a timeout.
+func (e *ConnectionError) Timeout() bool {
+	var ne interface{ Timeout() bool }
+	return errors.As(e.Cause, &ne) && ne.Timeout()
+}
+
 const connectionErrorMessage = "Failed to connect to Ollama. Please check that Ollama is downloaded, running and accessible. https://ollama.com/download"
@@ -1,9 +1,21 @@
 package ollama
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 )
 
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
 func TestNewResponseError_JSONAndRaw(t *testing.T) {
 	e := newResponseError(400, []byte(`{"error":"nope"}`))
 	if re, ok := e.(*ResponseError); !ok || re.Message != "nope" || re.StatusCode != 400 {
@@ -14,3 +26,82 @@ func TestNewResponseError_JSONAndRaw(t *testing.T) {
 		t.Fatalf("bad raw response error: %#v", e2)
 	}
 }
+
+func TestResponseError_Sentinels(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{404, `{"error":"model 'llama9' not found, try pulling it first"}`, ErrModelNotFound},
+		{400, `{"error":"registry.ollama.ai/library/gemma:2b does not support tools"}`, ErrModelDoesNotSupportTools},
+		{400, `{"error":"\"deepseek\" does not support thinking"}`, ErrModelDoesNotSupportThinking},
+		{400, `{"error":"input length exceeds maximum context length"}`, ErrContextTooLong},
+		{401, `{"error":"unauthorized"}`, ErrUnauthorized},
+		{503, `{"error":"server busy, please try again.  maximum pending requests exceeded"}`, ErrServerOverloaded},
+		{429, `too many requests`, ErrServerOverloaded},
+		{500, `{"error":"llama runner process has terminated"}`, ErrServerError},
+		{404, `404 page not found`, nil},
+	}
+	all := []error{ErrModelNotFound, ErrContextTooLong, ErrModelDoesNotSupportTools, ErrModelDoesNotSupportThinking, ErrUnauthorized, ErrServerOverloaded, ErrServerError}
+	for _, tc := range cases {
+		err := newResponseError(tc.status, []byte(tc.body))
+		for _, s := range all {
+			if got := errors.Is(err, s); got != (s == tc.want) {
+				t.Errorf("%d %s: errors.Is(%v) = %v", tc.status, tc.body, s, got)
+			}
+		}
+		if string(err.(*ResponseError).Body) != tc.body {
+			t.Errorf("body not kept: %q", err.(*ResponseError).Body)
+		}
+	}
+}
+
+func TestResponseError_KeepsHeadersAndStreamErrorsClassify(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/show" {
+			w.Header().Set("X-Trace", "abc")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":"model 'x' not found"}`)
+			return
+		}
+		_, _ = io.WriteString(w, "{\"error\":\"model \\\"x\\\" not found, try pulling it first\"}\n")
+	})
+	defer srv.Close()
+	_, err := c.Show(context.Background(), "x")
+	var re *ResponseError
+	if !errors.As(err, &re) || re.Header.Get("X-Trace") != "abc" || !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("unexpected: %#v", err)
+	}
+	s, err := c.ChatStream(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	if _, err := s.Recv(); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("stream error not classified: %v", err)
+	}
+}
+
+func TestConnectionError_WrapsCause(t *testing.T) {
+	dnsErr := &net.DNSError{Err: "no such host", Name: "ollama.invalid", IsNotFound: true}
+	c := NewClient("http://ollama.invalid:11434", WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, &url.Error{Op: "Get", URL: r.URL.String(), Err: &net.OpError{Op: "dial", Net: "tcp", Err: dnsErr}}
+	})}))
+	_, err := c.List(context.Background())
+	var ce *ConnectionError
+	var got *net.DNSError
+	if !errors.As(err, &ce) || !errors.As(err, &got) || got.Name != "ollama.invalid" || ce.Timeout() {
+		t.Fatalf("unexpected: %#v", err)
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	c = NewClient(slow.URL, WithHTTPClient(&http.Client{Timeout: 20 * time.Millisecond}))
+	_, err = c.List(context.Background())
+	if !errors.As(err, &ce) || !ce.Timeout() {
+		t.Fatalf("expected timeout ConnectionError, got %#v", err)
+	}
+}
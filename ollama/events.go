@@ -0,0 +1,145 @@
+package ollama
+
+// Event is one typed piece of a chat or generate stream: a ThinkingDelta,
+// ContentDelta, ToolCallEvent or Done.
+type Event interface{ isEvent() }
+
+// ThinkingDelta carries the next piece of the model's reasoning.
+type ThinkingDelta struct{ Text string }
+
+// ContentDelta carries the next piece of the answer.
+type ContentDelta struct{ Text string }
+
+// ToolCallEvent carries one tool call requested by the model.
+type ToolCallEvent struct{ Call ToolCall }
+
+// Done is the last event of a stream. Metrics holds the final timings,
+// token counts and done_reason; Context is set for generate streams.
+type Done struct {
+	Metrics BaseGenerateResponse
+	Context []int
+}
+
+func (ThinkingDelta) isEvent() {}
+func (ContentDelta) isEvent()  {}
+func (ToolCallEvent) isEvent() {}
+func (Done) isEvent()          {}
+
+// EventStream turns a chat or generate stream into typed events, so callers
+// need not inspect each chunk's optional fields. Empty deltas are skipped.
+type EventStream struct {
+	next    func() ([]Event, error)
+	close   func() error
+	pending []Event
+}
+
+// ChatEvents wraps s. Closing the EventStream closes s.
+func ChatEvents(s *Stream[ChatResponse]) *EventStream {
+	return &EventStream{
+		close: s.Close,
+		next: func() ([]Event, error) {
+			c, err := s.Recv()
+			if err != nil {
+				return nil, err
+			}
+			var evs []Event
+			m := &c.Message
+			if m.Thinking != nil && *m.Thinking != "" {
+				evs = append(evs, ThinkingDelta{Text: *m.Thinking})
+			}
+			if m.Content != nil && *m.Content != "" {
+				evs = append(evs, ContentDelta{Text: *m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				evs = append(evs, ToolCallEvent{Call: tc})
+			}
+			if c.Done != nil && *c.Done {
+				evs = append(evs, Done{Metrics: c.BaseGenerateResponse})
+			}
+			return evs, nil
+		},
+	}
+}
+
+// GenerateEvents wraps s. Closing the EventStream closes s.
+func GenerateEvents(s *Stream[GenerateResponse]) *EventStream {
+	return &EventStream{
+		close: s.Close,
+		next: func() ([]Event, error) {
+			c, err := s.Recv()
+			if err != nil {
+				return nil, err
+			}
+			var evs []Event
+			if c.Thinking != nil && *c.Thinking != "" {
+				evs = append(evs, ThinkingDelta{Text: *c.Thinking})
+			}
+			if c.Response != "" {
+				evs = append(evs, ContentDelta{Text: c.Response})
+			}
+			if c.Done != nil && *c.Done {
+				evs = append(evs, Done{Metrics: c.BaseGenerateResponse, Context: c.Context})
+			}
+			return evs, nil
+		},
+	}
+}
+
+// Recv returns the next event, or EOF once the stream ends.
+func (e *EventStream) Recv() (Event, error) {
+	for len(e.pending) == 0 {
+		evs, err := e.next()
+		if err != nil {
+			return nil, err
+		}
+		e.pending = evs
+	}
+	ev := e.pending[0]
+	e.pending = e.pending[1:]
+	return ev, nil
+}
+
+// Close closes the underlying stream.
+func (e *EventStream) Close() error { return e.close() }
+
+// EventHandlers receives events from EventStream.Run. Nil handlers are
+// skipped.
+type EventHandlers struct {
+	OnThinking func(text string)
+	OnContent  func(text string)
+	OnToolCall func(call ToolCall)
+	OnDone     func(done Done)
+}
+
+// Run delivers every event to h until the stream ends, then closes it. It
+// returns nil at EOF and otherwise the stream's error.
+func (e *EventStream) Run(h EventHandlers) error {
+	defer func() { _ = e.Close() }()
+	for {
+		ev, err := e.Recv()
+		if err == EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch ev := ev.(type) {
+		case ThinkingDelta:
+			if h.OnThinking != nil {
+				h.OnThinking(ev.Text)
+			}
+		case ContentDelta:
+			if h.OnContent != nil {
+				h.OnContent(ev.Text)
+			}
+		case ToolCallEvent:
+			if h.OnToolCall != nil {
+				h.OnToolCall(ev.Call)
+			}
+		case Done:
+			if h.OnDone != nil {
+				h.OnDone(ev)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const reasoningChat = `{"message":{"role":"assistant","content":"","thinking":"hmm"}}
+{"message":{"role":"assistant","content":"","thinking":" ok"}}
+{"message":{"role":"assistant","content":"Hi","tool_calls":[{"function":{"name":"lookup","arguments":{"q":"x"}}}]}}
+{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","eval_count":3}
+`
+
+func TestChatEvents_Recv(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, reasoningChat)
+	})
+	defer srv.Close()
+	s, err := c.ChatStream(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	es := ChatEvents(s)
+	defer func() { _ = es.Close() }()
+	var got []string
+	for {
+		ev, err := es.Recv()
+		if err == EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch ev := ev.(type) {
+		case ThinkingDelta:
+			got = append(got, "think:"+ev.Text)
+		case ContentDelta:
+			got = append(got, "content:"+ev.Text)
+		case ToolCallEvent:
+			got = append(got, "tool:"+ev.Call.Function.Name)
+		case Done:
+			got = append(got, fmt.Sprintf("done:%s:%d", *ev.Metrics.DoneReason, *ev.Metrics.EvalCount))
+		}
+	}
+	want := "think:hmm|think: ok|content:Hi|tool:lookup|done:stop:3"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("events: %v", got)
+	}
+}
+
+func TestGenerateEvents_Run(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"\",\"thinking\":\"t\"}\n{\"response\":\"a\"}\n{\"response\":\"b\"}\n{\"response\":\"\",\"done\":true,\"context\":[7]}\n")
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content, thinking strings.Builder
+	var done Done
+	err = GenerateEvents(s).Run(EventHandlers{
+		OnContent:  func(s string) { content.WriteString(s) },
+		OnThinking: func(s string) { thinking.WriteString(s) },
+		OnDone:     func(d Done) { done = d },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content.String() != "ab" || thinking.String() != "t" || len(done.Context) != 1 || done.Context[0] != 7 {
+		t.Fatalf("content=%q thinking=%q done=%+v", content.String(), thinking.String(), done)
+	}
+}
@@ -81,3 +81,31 @@ func parseHost(in string) string {
 	}
 	return base
 }
+
+// parseUnixHost recognizes unix socket hosts:
+//   - unix:///path/to/ollama.sock
+//   - http+unix://%2Fpath%2Fto%2Follama.sock/optional/prefix
+//
+// It returns the socket path and any URL path prefix.
+func parseUnixHost(in string) (socket, prefix string, ok bool) {
+	host := strings.TrimSpace(in)
+	if rest, found := strings.CutPrefix(host, "unix://"); found {
+		if rest == "" {
+			return "", "", false
+		}
+		return rest, "", true
+	}
+	rest, found := strings.CutPrefix(host, "http+unix://")
+	if !found {
+		return "", "", false
+	}
+	enc, path, _ := strings.Cut(rest, "/")
+	sock, err := url.PathUnescape(enc)
+	if err != nil || sock == "" {
+		return "", "", false
+	}
+	if path = strings.Trim(path, "/"); path != "" {
+		prefix = "/" + path
+	}
+	return sock, prefix, true
+}
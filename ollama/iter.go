@@ -0,0 +1,72 @@
+//go:build go1.23
+
+package ollama
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the stream's chunks for use with range. A
+// failed Recv is yielded once as (nil, err) and ends the iteration; EOF ends
+// it without an error. The stream is closed when the loop finishes, including
+// on break.
+//
+//	for chunk, err := range stream.All() {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Print(chunk.Message.GetContent())
+//	}
+func (s *Stream[T]) All() iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		defer func() { _ = s.Close() }()
+		for {
+			v, err := s.Recv()
+			if err == EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ListAll calls List when iterated and yields each installed model. A failed
+// call is yielded as a single error.
+func (c *Client) ListAll(ctx context.Context, opts ...CallOption) iter.Seq2[ListModel, error] {
+	return func(yield func(ListModel, error) bool) {
+		resp, err := c.List(ctx, opts...)
+		if err != nil {
+			yield(ListModel{}, err)
+			return
+		}
+		for _, m := range resp.Models {
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
+// PSAll calls PS when iterated and yields each running model. A failed call
+// is yielded as a single error.
+func (c *Client) PSAll(ctx context.Context, opts ...CallOption) iter.Seq2[ProcessModel, error] {
+	return func(yield func(ProcessModel, error) bool) {
+		resp, err := c.PS(ctx, opts...)
+		if err != nil {
+			yield(ProcessModel{}, err)
+			return
+		}
+		for _, m := range resp.Models {
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
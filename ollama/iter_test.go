@@ -0,0 +1,106 @@
+//go:build go1.23
+
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestStream_All(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"a\"}\n{\"response\":\"b\",\"done\":true}\n")
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for chunk, err := range s.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got += chunk.Response
+	}
+	if got != "ab" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestStream_AllBreakCloses(t *testing.T) {
+	gone := make(chan struct{})
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"a\"}\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		close(gone)
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for chunk, err := range s.All() {
+		if err != nil || chunk.Response != "a" {
+			t.Fatalf("chunk=%v err=%v", chunk, err)
+		}
+		break
+	}
+	<-gone
+}
+
+func TestStream_AllYieldsError(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"a\"}\n{\"error\":\"boom\"}\n")
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	var last error
+	for _, err := range s.All() {
+		n++
+		last = err
+	}
+	var re *ResponseError
+	if n != 2 || !errors.As(last, &re) || re.Message != "boom" {
+		t.Fatalf("n=%d err=%v", n, last)
+	}
+}
+
+func TestClient_ListAllPSAll(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = io.WriteString(w, `{"models":[{"model":"a"},{"model":"b"},{"model":"c"}]}`)
+		case "/api/ps":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = io.WriteString(w, `{"error":"down"}`)
+		}
+	})
+	defer srv.Close()
+	var names []string
+	for m, err := range c.ListAll(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, *m.Model)
+		if len(names) == 2 {
+			break
+		}
+	}
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Fatalf("got %v", names)
+	}
+	for _, err := range c.PSAll(context.Background()) {
+		if !errors.Is(err, ErrServerError) {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// WithLogger logs calls to l. Requests, responses and stream lifecycle
+// (open, chunk count, done_reason, close) are logged at debug level; retries
+// and errors at warn level. Request bodies are logged with base64 images
+// replaced by their size, and credentials in headers are redacted.
+func WithLogger(l *slog.Logger) ClientOption {
+	return func(c *Client) { c.logger = l }
+}
+
+// logAttrs prefixes extra with the attributes identifying the call.
+func (t *callTrace) logAttrs(extra ...slog.Attr) []any {
+	out := make([]any, 0, len(extra)+3)
+	out = append(out, slog.String("method", t.req.Method), slog.String("path", t.req.Path))
+	if t.req.Model != "" {
+		out = append(out, slog.String("model", t.req.Model))
+	}
+	for _, a := range extra {
+		out = append(out, a)
+	}
+	return out
+}
+
+// errAttr returns an "err" attribute, or an empty one (which handlers drop)
+// for a nil error.
+func errAttr(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Any("err", err)
+}
+
+// logRequest logs one attempt as it is sent.
+func (c *Client) logRequest(ctx context.Context, r *Request, req *http.Request, body []byte) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("path", r.Path),
+		slog.String("host", req.URL.Host),
+		slog.Int64("bytes", req.ContentLength),
+		slog.Any("header", redactedHeader(req.Header)),
+	}
+	if r.Model != "" {
+		attrs = append(attrs, slog.String("model", r.Model))
+	}
+	if body != nil {
+		attrs = append(attrs, slog.Any("body", loggedBody(body)))
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "ollama request", attrs...)
+}
+
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+}
+
+// redactedHeader logs headers with credentials replaced.
+type redactedHeader http.Header
+
+func (h redactedHeader) LogValue() slog.Value {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		v := strings.Join(h[k], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+			v = "REDACTED"
+		}
+		attrs = append(attrs, slog.String(k, v))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// loggedBody logs a JSON request body with image payloads replaced by their
+// size. It is only decoded when the record is actually written.
+type loggedBody []byte
+
+func (b loggedBody) LogValue() slog.Value {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return slog.StringValue(fmt.Sprintf("<%d bytes>", len(b)))
+	}
+	out, err := encodeJSON(redactImages(v))
+	if err != nil {
+		return slog.StringValue(fmt.Sprintf("<%d bytes>", len(b)))
+	}
+	return slog.StringValue(string(out))
+}
+
+// redactImages walks decoded JSON and replaces every string under an
+// "images" key.
+func redactImages(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, e := range x {
+			if imgs, ok := e.([]any); ok && k == "images" {
+				for i, img := range imgs {
+					if s, ok := img.(string); ok {
+						imgs[i] = fmt.Sprintf("<image: %d bytes base64>", len(s))
+					}
+				}
+				continue
+			}
+			x[k] = redactImages(e)
+		}
+	case []any:
+		for i, e := range x {
+			x[i] = redactImages(e)
+		}
+	}
+	return v
+}
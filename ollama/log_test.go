@@ -0,0 +1,124 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logRecords decodes JSON log lines into maps.
+func logRecords(t *testing.T, b *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("bad log line %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func newDebugLogger(b *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestLogger_RedactsImagesAndAuth(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`)
+	})
+	defer srv.Close()
+	var buf bytes.Buffer
+	WithLogger(newDebugLogger(&buf))(c)
+	WithAPIKey("sk-secret")(c)
+
+	img := bytes.Repeat([]byte{0xff}, 300)
+	_, err := c.Chat(context.Background(), &ChatRequest{
+		BaseStreamableRequest: BaseStreamableRequest{Model: "llava"},
+		Messages:              []Message{{Role: "user", Content: StrPtr("what is this"), Images: []Image{{Value: img}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "sk-secret") || strings.Contains(out, "/////") {
+		t.Fatalf("secrets leaked: %s", out)
+	}
+	recs := logRecords(t, &buf)
+	if len(recs) != 2 || recs[0]["msg"] != "ollama request" || recs[1]["msg"] != "ollama response" {
+		t.Fatalf("records: %v", recs)
+	}
+	req := recs[0]
+	if req["model"] != "llava" || req["header"].(map[string]any)["Authorization"] != "REDACTED" {
+		t.Fatalf("request record: %v", req)
+	}
+	if !strings.Contains(req["body"].(string), "<image: 400 bytes base64>") || !strings.Contains(req["body"].(string), "what is this") {
+		t.Fatalf("body: %v", req["body"])
+	}
+	if recs[1]["status"] != float64(200) || recs[1]["bytes"].(float64) == 0 {
+		t.Fatalf("response record: %v", recs[1])
+	}
+}
+
+func TestLogger_StreamLifecycle(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"a\"}\n{\"response\":\"b\",\"done\":true,\"done_reason\":\"length\"}\n")
+	})
+	defer srv.Close()
+	var buf bytes.Buffer
+	WithLogger(newDebugLogger(&buf))(c)
+
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := s.Recv(); err != nil {
+			break
+		}
+	}
+	_ = s.Close()
+	recs := logRecords(t, &buf)
+	var msgs []string
+	for _, r := range recs {
+		msgs = append(msgs, r["msg"].(string))
+	}
+	if strings.Join(msgs, ",") != "ollama request,ollama stream open,ollama stream close" {
+		t.Fatalf("messages: %v", msgs)
+	}
+	if end := recs[2]; end["chunks"] != float64(2) || end["done_reason"] != "length" {
+		t.Fatalf("close record: %v", end)
+	}
+}
+
+func TestLogger_RetriesAndErrorsAtWarn(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"error":"slow down"}`)
+	})
+	defer srv.Close()
+	var buf bytes.Buffer
+	WithLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))(c)
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1})(c)
+
+	if _, err := c.List(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+	recs := logRecords(t, &buf)
+	if len(recs) != 2 || recs[0]["msg"] != "ollama retry" || recs[1]["msg"] != "ollama error" {
+		t.Fatalf("records: %v", recs)
+	}
+	if recs[0]["status"] != float64(429) || !strings.Contains(recs[1]["err"].(string), "slow down") {
+		t.Fatalf("records: %v", recs)
+	}
+}
@@ -0,0 +1,249 @@
+package ollama
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics collects client traffic in Prometheus form without third-party
+// dependencies. Attach it with WithMetrics and serve Handler to scrape it.
+// A Metrics may be shared by several clients.
+//
+// Exposed series:
+//
+//	ollama_client_requests_total{endpoint,model,status}
+//	ollama_client_retries_total{endpoint,model}
+//	ollama_client_request_duration_seconds{endpoint,model} (histogram)
+//	ollama_client_time_to_first_chunk_seconds{endpoint,model} (histogram)
+//	ollama_client_prompt_tokens_total{model}
+//	ollama_client_eval_tokens_total{model}
+//	ollama_client_eval_tokens_per_second{model} (histogram)
+//
+// status is the HTTP status code of the final attempt, or "error" when no
+// response was received or a successful response failed later, such as a
+// stream carrying an error chunk or cut short. Durations cover the whole
+// call, including reading the response or stream.
+type Metrics struct {
+	mu           sync.Mutex
+	requests     map[metricLabels]uint64
+	retries      map[metricLabels]uint64
+	duration     map[metricLabels]*histogram
+	firstChunk   map[metricLabels]*histogram
+	promptTokens map[metricLabels]uint64
+	evalTokens   map[metricLabels]uint64
+	throughput   map[metricLabels]*histogram
+}
+
+// metricLabels identifies one series; unused labels are empty and omitted.
+type metricLabels struct {
+	endpoint, model, status string
+}
+
+var (
+	latencyBuckets    = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+	throughputBuckets = []float64{1, 5, 10, 20, 30, 50, 75, 100, 150, 200, 300}
+)
+
+// NewMetrics returns an empty collector.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:     map[metricLabels]uint64{},
+		retries:      map[metricLabels]uint64{},
+		duration:     map[metricLabels]*histogram{},
+		firstChunk:   map[metricLabels]*histogram{},
+		promptTokens: map[metricLabels]uint64{},
+		evalTokens:   map[metricLabels]uint64{},
+		throughput:   map[metricLabels]*histogram{},
+	}
+}
+
+// WithMetrics records every call in m.
+func WithMetrics(m *Metrics) ClientOption {
+	return func(c *Client) { c.metrics = m }
+}
+
+// Handler serves the collected metrics in the Prometheus text exposition
+// format.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = m.WriteTo(w)
+	})
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	m.mu.Lock()
+	writeCounter(cw, "ollama_client_requests_total", "API calls by endpoint, model and final status.", m.requests)
+	writeCounter(cw, "ollama_client_retries_total", "Retried attempts by endpoint and model.", m.retries)
+	writeHistogram(cw, "ollama_client_request_duration_seconds", "Call duration including reading the response.", m.duration)
+	writeHistogram(cw, "ollama_client_time_to_first_chunk_seconds", "Time from sending a streaming call to its first decoded chunk.", m.firstChunk)
+	writeCounter(cw, "ollama_client_prompt_tokens_total", "Prompt tokens evaluated, from prompt_eval_count.", m.promptTokens)
+	writeCounter(cw, "ollama_client_eval_tokens_total", "Tokens generated, from eval_count.", m.evalTokens)
+	writeHistogram(cw, "ollama_client_eval_tokens_per_second", "Generation throughput, eval_count / eval_duration.", m.throughput)
+	m.mu.Unlock()
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// metricEndpoint strips queries and digests so paths have bounded
+// cardinality.
+func metricEndpoint(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	if strings.HasPrefix(path, "/api/blobs/") {
+		return "/api/blobs"
+	}
+	return path
+}
+
+func (m *Metrics) retry(req *Request) {
+	m.mu.Lock()
+	m.retries[metricLabels{endpoint: metricEndpoint(req.Path), model: req.Model}]++
+	m.mu.Unlock()
+}
+
+// observe records a finished call. status is 0 when no response arrived.
+func (m *Metrics) observe(req *Request, status int, failed bool, d time.Duration, first time.Duration, b *BaseGenerateResponse) {
+	l := metricLabels{endpoint: metricEndpoint(req.Path), model: req.Model}
+	code := "error"
+	if status >= 400 || status > 0 && !failed {
+		code = strconv.Itoa(status)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[metricLabels{endpoint: l.endpoint, model: l.model, status: code}]++
+	observeHistogram(m.duration, l, latencyBuckets, d.Seconds())
+	if first > 0 {
+		observeHistogram(m.firstChunk, l, latencyBuckets, first.Seconds())
+	}
+	if b == nil {
+		return
+	}
+	ml := metricLabels{model: req.Model}
+	if b.PromptEvalCount != nil {
+		m.promptTokens[ml] += uint64(*b.PromptEvalCount)
+	}
+	if b.EvalCount != nil {
+		m.evalTokens[ml] += uint64(*b.EvalCount)
+		if b.EvalDuration != nil && *b.EvalDuration > 0 {
+			observeHistogram(m.throughput, ml, throughputBuckets, float64(*b.EvalCount)/time.Duration(*b.EvalDuration).Seconds())
+		}
+	}
+}
+
+type histogram struct {
+	bounds []float64
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func observeHistogram(hs map[metricLabels]*histogram, l metricLabels, bounds []float64, v float64) {
+	h := hs[l]
+	if h == nil {
+		h = &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+		hs[l] = h
+	}
+	for i, b := range bounds {
+		if v <= b {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countWriter) printf(format string, args ...any) {
+	if c.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(c.w, format, args...)
+	c.n += int64(n)
+	c.err = err
+}
+
+func writeCounter(w *countWriter, name, help string, series map[metricLabels]uint64) {
+	w.printf("# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, l := range sortedLabels(series) {
+		w.printf("%s%s %d\n", name, l.format(""), series[l])
+	}
+}
+
+func writeHistogram(w *countWriter, name, help string, series map[metricLabels]*histogram) {
+	w.printf("# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, l := range sortedLabels(series) {
+		h := series[l]
+		var cum uint64
+		for i, b := range h.bounds {
+			cum += h.counts[i]
+			w.printf("%s_bucket%s %d\n", name, l.format(formatFloat(b)), cum)
+		}
+		w.printf("%s_bucket%s %d\n", name, l.format("+Inf"), h.count)
+		w.printf("%s_sum%s %s\n", name, l.format(""), formatFloat(h.sum))
+		w.printf("%s_count%s %d\n", name, l.format(""), h.count)
+	}
+}
+
+func sortedLabels[V any](series map[metricLabels]V) []metricLabels {
+	out := make([]metricLabels, 0, len(series))
+	for l := range series {
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.endpoint != b.endpoint {
+			return a.endpoint < b.endpoint
+		}
+		if a.model != b.model {
+			return a.model < b.model
+		}
+		return a.status < b.status
+	})
+	return out
+}
+
+// format renders the label set, adding le for histogram buckets. The
+// model label is always present so series line up across metrics.
+func (l metricLabels) format(le string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	if l.endpoint != "" {
+		b.WriteString(`endpoint="` + labelEscaper.Replace(l.endpoint) + `",`)
+	}
+	b.WriteString(`model="` + labelEscaper.Replace(l.model) + `"`)
+	if l.status != "" {
+		b.WriteString(`,status="` + l.status + `"`)
+	}
+	if le != "" {
+		b.WriteString(`,le="` + le + `"`)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,128 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics_CountsAndThroughput(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/generate":
+			_, _ = io.WriteString(w, "{\"response\":\"a\"}\n{\"response\":\"b\",\"done\":true,\"prompt_eval_count\":4,\"eval_count\":20,\"eval_duration\":1000000000}\n")
+		case "/api/show":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":"model 'x' not found"}`)
+		default:
+			_, _ = io.WriteString(w, `{"models":[]}`)
+		}
+	})
+	defer srv.Close()
+	m := NewMetrics()
+	WithMetrics(m)(c)
+	ctx := context.Background()
+
+	s, err := c.GenerateStream(ctx, &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "llama3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := s.Recv(); err != nil {
+			break
+		}
+	}
+	_ = s.Close()
+	_, _ = c.List(ctx)
+	_, _ = c.Show(ctx, "x")
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("content type: %q", rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		"# TYPE ollama_client_requests_total counter",
+		`ollama_client_requests_total{endpoint="/api/generate",model="llama3",status="200"} 1`,
+		`ollama_client_requests_total{endpoint="/api/tags",model="",status="200"} 1`,
+		`ollama_client_requests_total{endpoint="/api/show",model="x",status="404"} 1`,
+		`ollama_client_time_to_first_chunk_seconds_count{endpoint="/api/generate",model="llama3"} 1`,
+		`ollama_client_request_duration_seconds_bucket{endpoint="/api/tags",model="",le="+Inf"} 1`,
+		`ollama_client_prompt_tokens_total{model="llama3"} 4`,
+		`ollama_client_eval_tokens_total{model="llama3"} 20`,
+		`ollama_client_eval_tokens_per_second_bucket{model="llama3",le="20"} 1`,
+		`ollama_client_eval_tokens_per_second_bucket{model="llama3",le="10"} 0`,
+		`ollama_client_eval_tokens_per_second_sum{model="llama3"} 20`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetrics_ConnectionErrorsAndLabelEscaping(t *testing.T) {
+	m := NewMetrics()
+	c := NewClient("http://127.0.0.1:1", WithHTTPClient(&http.Client{Transport: failingRoundTripper{}}), WithMetrics(m))
+	_, _ = c.Embed(context.Background(), &EmbedRequest{Model: "we\"ird\nname", Input: "x"})
+
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `ollama_client_requests_total{endpoint="/api/embed",model="we\"ird\nname",status="error"} 1`
+	if !strings.Contains(b.String(), want) {
+		t.Fatalf("missing %q in:\n%s", want, b.String())
+	}
+}
+
+func TestMetrics_StreamErrorsCountAsErrors(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat":
+			_, _ = io.WriteString(w, "{\"message\":{\"role\":\"assistant\",\"content\":\"a\"}}\n{\"error\":\"runner crashed\"}\n")
+		default:
+			// cut short without done
+			_, _ = io.WriteString(w, "{\"response\":\"a\"}\n")
+		}
+	})
+	defer srv.Close()
+	m := NewMetrics()
+	WithMetrics(m)(c)
+	ctx := context.Background()
+
+	chat, err := c.ChatStream(ctx, &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chat.Collect(); err == nil {
+		t.Fatal("expected a stream error")
+	}
+	gen, err := c.GenerateStream(ctx, &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gen.Collect(); !errors.Is(err, ErrIncompleteStream) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var b strings.Builder
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`ollama_client_requests_total{endpoint="/api/chat",model="m",status="error"} 1`,
+		`ollama_client_requests_total{endpoint="/api/generate",model="m",status="error"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), `status="200"`) {
+		t.Errorf("failed streams counted as 200:\n%s", b.String())
+	}
+}
@@ -0,0 +1,72 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Request describes a single API call as seen by middleware.
+type Request struct {
+	Method string
+	Path   string
+	// Host is the base URL chosen for this attempt; middleware may override it.
+	Host string
+	// Model is the model named by the request body, if any.
+	Model string
+	// Body is the request struct (e.g. *GenerateRequest), an io.Reader for raw
+	// uploads, or nil. Structs are JSON-encoded by the innermost RoundTripFunc,
+	// so middleware may replace or modify it.
+	Body any
+	// Header holds per-request headers; they override client defaults.
+	Header http.Header
+	// Stream reports whether the response is consumed as an NDJSON stream.
+	Stream bool
+
+	trace    *callTrace
+	priority *Priority         // set by WithPriority
+	idle     *[2]time.Duration // set by WithCallIdleTimeout
+	stall    *stallTimer
+	host     string // where the last attempt was sent
+}
+
+// RoundTripFunc performs one attempt of an API call. Responses are returned
+// as-is, including error statuses; the client maps them to ResponseError
+// after the chain returns.
+type RoundTripFunc func(ctx context.Context, req *Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripFunc. A middleware may short-circuit by
+// returning a response without calling next; that response is then decoded
+// exactly like one from the server.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware appends middleware to the client's chain. Middleware runs in
+// registration order: the first registered is the outermost and sees the
+// request first and the response last. The chain runs once per attempt, so
+// retries are visible to every middleware.
+func WithMiddleware(mw ...Middleware) ClientOption {
+	return func(c *Client) { c.middleware = append(c.middleware, mw...) }
+}
+
+// chain composes the registered middleware around send. Authentication and
+// request signing are innermost so middleware never observes credentials.
+func (c *Client) chain() RoundTripFunc {
+	rt := c.authChain()
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	return rt
+}
+
+// authChain is send with authentication and request signing but without
+// middleware, for internal requests such as affinity probes.
+func (c *Client) authChain() RoundTripFunc {
+	rt := c.send
+	if c.signer != nil {
+		rt = c.sign(rt)
+	}
+	if c.tokens != nil {
+		rt = c.authenticate(rt)
+	}
+	return rt
+}
@@ -0,0 +1,119 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware_OrderAndRequestInfo(t *testing.T) {
+	var gotHeader string
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Request-ID")
+		_, _ = io.WriteString(w, `{"response":"ok"}`)
+	})
+	defer srv.Close()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(ctx context.Context, req *Request) (*http.Response, error) {
+				order = append(order, name+">")
+				resp, err := next(ctx, req)
+				order = append(order, "<"+name)
+				return resp, err
+			}
+		}
+	}
+	var seen *Request
+	inspect := func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			seen = req
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+			req.Header.Set("X-Request-ID", "abc")
+			return next(ctx, req)
+		}
+	}
+	WithMiddleware(trace("a"), trace("b"), inspect)(c)
+
+	req := &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}
+	if _, err := c.Generate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(order, " ") != "a> b> <b <a" {
+		t.Fatalf("order: %v", order)
+	}
+	if seen.Path != "/api/generate" || seen.Model != "m" || seen.Body != req || seen.Stream {
+		t.Fatalf("unexpected request: %+v", seen)
+	}
+	if gotHeader != "abc" {
+		t.Fatalf("header not sent: %q", gotHeader)
+	}
+}
+
+func TestMiddleware_ShortCircuitStream(t *testing.T) {
+	c := NewClient("", WithHTTPClient(&http.Client{Transport: failingRoundTripper{}}))
+	WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			if !req.Stream {
+				t.Fatalf("expected stream request")
+			}
+			body := "{\"message\":{\"role\":\"assistant\",\"content\":\"cached\"},\"done\":true}\n"
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}
+	})(c)
+
+	s, err := c.ChatStream(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	part, err := s.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part.Message.GetContent() != "cached" {
+		t.Fatalf("got %+v", part)
+	}
+}
+
+func TestMiddleware_SeesRawErrorResponse(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		_, _ = io.WriteString(w, `{"error":"not found"}`)
+	})
+	defer srv.Close()
+	var status int
+	WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			resp, err := next(ctx, req)
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			return resp, err
+		}
+	})(c)
+	_, err := c.Show(context.Background(), "m")
+	var re *ResponseError
+	if !errors.As(err, &re) || re.Message != "not found" {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if status != 404 {
+		t.Fatalf("middleware saw status %d", status)
+	}
+}
+
+func TestMiddleware_ErrorShortCircuit(t *testing.T) {
+	sentinel := errors.New("denied")
+	c := NewClient("", WithMiddleware(func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*http.Response, error) { return nil, sentinel }
+	}))
+	if _, err := c.PS(context.Background()); !errors.Is(err, sentinel) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BalanceStrategy selects how a multi-host client spreads requests.
+type BalanceStrategy int
+
+const (
+	// RoundRobin cycles through healthy hosts in order.
+	RoundRobin BalanceStrategy = iota
+	// LeastInFlight picks the healthy host with the fewest open requests,
+	// counting streams until they are closed.
+	LeastInFlight
+)
+
+const defaultHealthCheckInterval = 5 * time.Second
+
+// HostStatus is a snapshot of one host in a multi-host client.
+type HostStatus struct {
+	URL      string
+	Healthy  bool
+	InFlight int
+}
+
+// NewPool constructs a Client that balances requests across several Ollama
+// hosts. Each host is parsed like NewClient's host argument. Hosts that fail
+// to connect are marked unhealthy and re-probed in the background;
+// non-streaming calls fail over to the next host. Call Close to stop probing.
+func NewPool(hosts []string, opts ...ClientOption) *Client {
+	return NewClient(strings.Join(hosts, ","), opts...)
+}
+
+// WithBalanceStrategy sets how a multi-host client picks a host.
+func WithBalanceStrategy(s BalanceStrategy) ClientOption {
+	return func(c *Client) { c.balance = s }
+}
+
+// WithHealthCheckInterval sets how often unhealthy hosts are re-probed.
+func WithHealthCheckInterval(d time.Duration) ClientOption {
+	return func(c *Client) {
+		if d > 0 {
+			c.healthInterval = d
+		}
+	}
+}
+
+// Hosts reports the state of each host. A single-host client returns one
+// entry that is always healthy.
+func (c *Client) Hosts() []HostStatus {
+	if c.pool == nil {
+		return []HostStatus{{URL: c.base, Healthy: true}}
+	}
+	out := make([]HostStatus, len(c.pool.hosts))
+	for i, h := range c.pool.hosts {
+		out[i] = HostStatus{URL: h.base, Healthy: !h.down.Load(), InFlight: int(h.inflight.Load())}
+	}
+	return out
+}
+
+// Close stops background health checks started by a multi-host client. It is
+// safe to call on any client and more than once.
+func (c *Client) Close() error {
+	if c.pool != nil {
+		c.pool.close()
+	}
+	return nil
+}
+
+// errNoHosts is returned when a pool has no host left to try.
+var errNoHosts = errors.New("ollama: no hosts available")
+
+type poolHost struct {
+	base     string
+	inflight atomic.Int64
+	down     atomic.Bool
+	probing  atomic.Bool
+}
+
+type hostPool struct {
+	hosts    []*poolHost
+	strategy BalanceStrategy
+	interval time.Duration
+	hc       *http.Client
+	header   http.Header
+	affinity *affinityRouter
+	next     atomic.Uint64
+	done     chan struct{}
+	once     sync.Once
+}
+
+func newHostPool(c *Client, bases []string) *hostPool {
+	p := &hostPool{
+		strategy: c.balance,
+		interval: c.healthInterval,
+		hc:       c.hc,
+		header:   c.header,
+		done:     make(chan struct{}),
+	}
+	if p.interval <= 0 {
+		p.interval = defaultHealthCheckInterval
+	}
+	for _, b := range bases {
+		p.hosts = append(p.hosts, &poolHost{base: b})
+	}
+	return p
+}
+
+// splitHosts parses a comma-separated host list, skipping empty entries.
+func splitHosts(in string) []string {
+	var out []string
+	for _, h := range strings.Split(in, ",") {
+		if strings.TrimSpace(h) == "" {
+			continue
+		}
+		out = append(out, parseHost(h))
+	}
+	return out
+}
+
+// candidates returns the hosts not in tried, preferring healthy ones. When
+// every remaining host is marked down they are all returned, so a pool whose
+// hosts all blipped at once recovers without waiting for a probe.
+func (p *hostPool) candidates(tried []*poolHost) []*poolHost {
+	var out []*poolHost
+	for _, h := range p.hosts {
+		if !h.down.Load() && !containsHost(tried, h) {
+			out = append(out, h)
+		}
+	}
+	if len(out) == 0 {
+		for _, h := range p.hosts {
+			if !containsHost(tried, h) {
+				out = append(out, h)
+			}
+		}
+	}
+	return out
+}
+
+// pick chooses a host for req among those not in tried, or nil if none remain.
+func (p *hostPool) pick(ctx context.Context, req *Request, tried []*poolHost) *poolHost {
+	cands := p.candidates(tried)
+	if len(cands) == 0 {
+		return nil
+	}
+	if p.affinity != nil && req.Model != "" && affinityPaths[req.Path] {
+		if h := p.affinity.choose(ctx, cands, req.Model); h != nil {
+			return h
+		}
+	}
+	if p.strategy == LeastInFlight {
+		return leastBusy(cands, nil)
+	}
+	return cands[(p.next.Add(1)-1)%uint64(len(cands))]
+}
+
+func containsHost(hs []*poolHost, h *poolHost) bool {
+	for _, x := range hs {
+		if x == h {
+			return true
+		}
+	}
+	return false
+}
+
+// markDown flags h unhealthy and starts a background probe if none is running.
+func (p *hostPool) markDown(h *poolHost) {
+	h.down.Store(true)
+	if !h.probing.CompareAndSwap(false, true) {
+		return
+	}
+	go p.probe(h)
+}
+
+// probe polls h until it answers or the pool is closed.
+func (p *hostPool) probe(h *poolHost) {
+	defer h.probing.Store(false)
+	t := time.NewTicker(p.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-t.C:
+		}
+		if p.check(h) {
+			h.down.Store(false)
+			return
+		}
+	}
+}
+
+// check issues a lightweight version request against h.
+func (p *hostPool) check(h *poolHost) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.base+"/api/version", nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("User-Agent", p.header.Get("User-Agent"))
+	resp, err := p.hc.Do(req)
+	if err != nil {
+		return false
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return resp.StatusCode < 500
+}
+
+func (p *hostPool) close() {
+	p.once.Do(func() { close(p.done) })
+}
+
+// roundTrip runs one attempt through rt, choosing a host for it. With a pool,
+// connection failures mark the host down and non-streaming calls fail over to
+// the remaining hosts; hosts rejected by an open circuit are skipped.
+func (c *Client) roundTrip(ctx context.Context, rt RoundTripFunc, req *Request) (*http.Response, error) {
+	if c.pool == nil {
+		r := *req
+		r.Header = req.Header.Clone()
+		r.Host = c.base
+		resp, err := rt(ctx, &r)
+		req.host = r.Host
+		return resp, err
+	}
+	var tried []*poolHost
+	lastErr := errNoHosts
+	for {
+		h := c.pool.pick(ctx, req, tried)
+		if h == nil {
+			return nil, lastErr
+		}
+		r := *req
+		r.Header = req.Header.Clone()
+		r.Host = h.base
+		h.inflight.Add(1)
+		resp, err := rt(ctx, &r)
+		req.host = r.Host
+		if err != nil {
+			h.inflight.Add(-1)
+			if errors.Is(err, ErrCircuitOpen) {
+				// nothing was sent, so even streams can move on
+				tried = append(tried, h)
+				lastErr = err
+				continue
+			}
+			if isConnectErr(err) && ctx.Err() == nil {
+				c.pool.markDown(h)
+				if !req.Stream {
+					tried = append(tried, h)
+					lastErr = err
+					continue
+				}
+			}
+			return nil, err
+		}
+		if c.pool.affinity != nil && resp.StatusCode < 400 && affinityPaths[req.Path] {
+			c.pool.affinity.noteServed(h, req.Model)
+		}
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { h.inflight.Add(-1) }}
+		return resp, nil
+	}
+}
+
+// releaseBody runs release once when the response body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,162 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// hostsTransport answers every request with the host name it was sent to,
+// refusing connections to hosts marked down.
+type hostsTransport struct {
+	mu   sync.Mutex
+	down map[string]bool
+	hits map[string]int
+}
+
+func newHostsTransport() *hostsTransport {
+	return &hostsTransport{down: map[string]bool{}, hits: map[string]int{}}
+}
+
+func (t *hostsTransport) setDown(host string, down bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.down[host] = down
+}
+
+func (t *hostsTransport) count(host string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.hits[host]
+}
+
+func (t *hostsTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.down[r.URL.Hostname()] {
+		return nil, &url.Error{Op: r.Method, URL: r.URL.String(), Err: syscall.ECONNREFUSED}
+	}
+	t.hits[r.URL.Hostname()]++
+	body := `{"response":"` + r.URL.Hostname() + `","done":true}` + "\n"
+	return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+}
+
+func generateHost(t *testing.T, c *Client) string {
+	t.Helper()
+	out, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out.Response
+}
+
+func TestPool_RoundRobin(t *testing.T) {
+	rt := newHostsTransport()
+	c := NewPool([]string{"a:1", "b:1", "c:1"}, WithHTTPClient(&http.Client{Transport: rt}))
+	defer func() { _ = c.Close() }()
+	var got []string
+	for i := 0; i < 6; i++ {
+		got = append(got, generateHost(t, c))
+	}
+	if strings.Join(got, "") != "abcabc" {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestPool_FailoverAndRecovery(t *testing.T) {
+	rt := newHostsTransport()
+	rt.setDown("a", true)
+	c := NewPool([]string{"a:1", "b:1"}, WithHTTPClient(&http.Client{Transport: rt}), WithHealthCheckInterval(10*time.Millisecond))
+	defer func() { _ = c.Close() }()
+
+	for i := 0; i < 3; i++ {
+		if h := generateHost(t, c); h != "b" {
+			t.Fatalf("request %d went to %q", i, h)
+		}
+	}
+	if st := c.Hosts(); st[0].Healthy || !st[1].Healthy {
+		t.Fatalf("unexpected health: %+v", st)
+	}
+
+	rt.setDown("a", false)
+	deadline := time.Now().Add(2 * time.Second)
+	for !c.Hosts()[0].Healthy {
+		if time.Now().After(deadline) {
+			t.Fatal("host a never recovered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if rt.count("a") == 0 {
+		t.Fatal("expected probe to reach host a")
+	}
+}
+
+func TestPool_AllDownReturnsConnectionError(t *testing.T) {
+	rt := newHostsTransport()
+	rt.setDown("a", true)
+	rt.setDown("b", true)
+	c := NewPool([]string{"a:1", "b:1"}, WithHTTPClient(&http.Client{Transport: rt}))
+	defer func() { _ = c.Close() }()
+	_, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	var ce *ConnectionError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected ConnectionError, got %v", err)
+	}
+}
+
+func TestPool_StreamDoesNotFailOver(t *testing.T) {
+	rt := newHostsTransport()
+	rt.setDown("a", true)
+	c := NewPool([]string{"a:1", "b:1"}, WithHTTPClient(&http.Client{Transport: rt}))
+	defer func() { _ = c.Close() }()
+	if _, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}); err == nil {
+		t.Fatal("expected stream to fail on host a")
+	}
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatalf("expected next stream to skip unhealthy host: %v", err)
+	}
+	_ = s.Close()
+}
+
+func TestPool_LeastInFlight(t *testing.T) {
+	rt := newHostsTransport()
+	c := NewPool([]string{"a:1", "b:1"}, WithHTTPClient(&http.Client{Transport: rt}), WithBalanceStrategy(LeastInFlight))
+	defer func() { _ = c.Close() }()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Hosts()[0].InFlight != 1 {
+		t.Fatalf("unexpected in-flight: %+v", c.Hosts())
+	}
+	for i := 0; i < 3; i++ {
+		if h := generateHost(t, c); h != "b" {
+			t.Fatalf("request %d went to %q", i, h)
+		}
+	}
+	_ = s.Close()
+	if c.Hosts()[0].InFlight != 0 {
+		t.Fatalf("stream close did not release: %+v", c.Hosts())
+	}
+}
+
+func TestNewClient_CommaSeparatedEnvHost(t *testing.T) {
+	old := os.Getenv("OLLAMA_HOST")
+	defer func() { _ = os.Setenv("OLLAMA_HOST", old) }()
+	_ = os.Setenv("OLLAMA_HOST", "one:1111, two:2222")
+	c := NewClient("")
+	defer func() { _ = c.Close() }()
+	st := c.Hosts()
+	if len(st) != 2 || st[0].URL != "http://one:1111" || st[1].URL != "http://two:2222" {
+		t.Fatalf("unexpected hosts: %+v", st)
+	}
+}
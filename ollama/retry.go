@@ -0,0 +1,187 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how Client.do retries failed attempts.
+//
+// Retries happen only before a response is handed back to the caller, so a
+// streaming body is never replayed once its first byte has been received.
+// Connection failures and the configured status codes are always retried;
+// other transport errors are retried only for idempotent methods, since the
+// server may already have acted on the request.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the computed exponential delay.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each attempt.
+	Multiplier float64
+	// Jitter randomly shortens each delay by up to this fraction (0..1).
+	Jitter float64
+	// RetryStatusCodes lists HTTP status codes that trigger a retry.
+	RetryStatusCodes []int
+	// OnRetry, when set, is called before sleeping ahead of each retry.
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	Method     string
+	Path       string
+	Attempt    int // the attempt that failed, starting at 1
+	StatusCode int // zero when the attempt failed without a response
+	Err        error
+	Delay      time.Duration
+}
+
+// DefaultRetryPolicy returns a policy with three attempts, exponential backoff
+// starting at 250ms, 20% jitter, and retries on 429, 502 and 503.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:      3,
+		InitialBackoff:   250 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		Multiplier:       2,
+		Jitter:           0.2,
+		RetryStatusCodes: []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable},
+	}
+}
+
+// WithRetryPolicy enables retries. Zero fields take DefaultRetryPolicy values,
+// except Jitter and OnRetry which are used as given.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) {
+		def := DefaultRetryPolicy()
+		if p.MaxAttempts <= 0 {
+			p.MaxAttempts = def.MaxAttempts
+		}
+		if p.InitialBackoff <= 0 {
+			p.InitialBackoff = def.InitialBackoff
+		}
+		if p.MaxBackoff <= 0 {
+			p.MaxBackoff = def.MaxBackoff
+		}
+		if p.Multiplier < 1 {
+			p.Multiplier = def.Multiplier
+		}
+		if p.RetryStatusCodes == nil {
+			p.RetryStatusCodes = def.RetryStatusCodes
+		}
+		c.retry = &p
+	}
+}
+
+// retryable reports whether an attempt outcome qualifies for another try.
+func (p *RetryPolicy) retryable(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, ErrCircuitOpen) {
+			return false
+		}
+		if isConnectErr(err) {
+			return true
+		}
+		return isIdempotent(method)
+	}
+	for _, code := range p.RetryStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay after the given failed attempt, preferring the
+// server's Retry-After header when present.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return d
+		}
+	}
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d -= d * math.Min(p.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// parseRetryAfter accepts either delay-seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodPut:
+		return true
+	}
+	return false
+}
+
+// fitsDeadline reports whether waiting d still leaves time before ctx expires.
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Now().Add(d).Before(deadline)
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// rewind resets a replayable request body before another attempt. Structs are
+// re-encoded on every attempt; raw readers must be seekable. It reports false
+// when the body cannot be replayed.
+func rewind(body any) bool {
+	if _, ok := body.(io.Reader); !ok {
+		return true
+	}
+	s, ok := body.(io.Seeker)
+	if !ok {
+		return false
+	}
+	_, err := s.Seek(0, io.SeekStart)
+	return err == nil
+}
@@ -0,0 +1,172 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetry_StatusThenSuccess(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, `{"response":"ok"}`)
+	})
+	defer srv.Close()
+	var events []RetryEvent
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, OnRetry: func(ev RetryEvent) { events = append(events, ev) }})(c)
+
+	out, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Response != "ok" || calls.Load() != 3 {
+		t.Fatalf("resp=%q calls=%d", out.Response, calls.Load())
+	}
+	if len(events) != 2 || events[0].StatusCode != 503 || events[1].Attempt != 2 || events[0].Path != "/api/generate" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if bodies[0] == "" || bodies[0] != bodies[2] {
+		t.Fatalf("body not replayed: %q", bodies)
+	}
+}
+
+func TestRetry_ExhaustedReturnsResponseError(t *testing.T) {
+	var calls atomic.Int32
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"error":"busy"}`)
+	})
+	defer srv.Close()
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})(c)
+	_, err := c.List(context.Background())
+	var re *ResponseError
+	if !errors.As(err, &re) || re.StatusCode != 429 || re.Message != "busy" {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls=%d", calls.Load())
+	}
+}
+
+func TestRetry_NonRetryableStatus(t *testing.T) {
+	var calls atomic.Int32
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer srv.Close()
+	WithRetryPolicy(DefaultRetryPolicy())(c)
+	if _, err := c.List(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls=%d", calls.Load())
+	}
+}
+
+func TestRetry_RetryAfterBeyondDeadline(t *testing.T) {
+	var calls atomic.Int32
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer srv.Close()
+	WithRetryPolicy(DefaultRetryPolicy())(c)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := c.List(ctx)
+	var re *ResponseError
+	if !errors.As(err, &re) || re.StatusCode != 503 {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if calls.Load() != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("calls=%d elapsed=%s", calls.Load(), time.Since(start))
+	}
+}
+
+type flakyRoundTripper struct {
+	fails int
+	err   error
+	calls int
+}
+
+func (f *flakyRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.calls++
+	if f.calls <= f.fails {
+		return nil, &url.Error{Op: r.Method, URL: r.URL.String(), Err: f.err}
+	}
+	return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{\"response\":\"x\",\"done\":true}\n")), Request: r}, nil
+}
+
+func TestRetry_ConnectionRefusedStream(t *testing.T) {
+	rt := &flakyRoundTripper{fails: 2, err: syscall.ECONNREFUSED}
+	c := NewClient("", WithHTTPClient(&http.Client{Transport: rt}), WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}))
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	if rt.calls != 3 {
+		t.Fatalf("calls=%d", rt.calls)
+	}
+}
+
+func TestRetry_TransportErrorOnlyForIdempotent(t *testing.T) {
+	rt := &flakyRoundTripper{fails: 1, err: io.ErrUnexpectedEOF}
+	c := NewClient("", WithHTTPClient(&http.Client{Transport: rt}), WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}))
+	if _, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}); err == nil {
+		t.Fatal("expected POST not to be replayed")
+	}
+	rt = &flakyRoundTripper{fails: 1, err: io.ErrUnexpectedEOF}
+	c = NewClient("", WithHTTPClient(&http.Client{Transport: rt}), WithRetryPolicy(RetryPolicy{InitialBackoff: time.Millisecond}))
+	if _, err := c.PS(context.Background()); err != nil {
+		t.Fatalf("expected GET to be retried: %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d, ok := parseRetryAfter("3", now); !ok || d != 3*time.Second {
+		t.Fatalf("seconds: %v %v", d, ok)
+	}
+	if d, ok := parseRetryAfter(now.Add(2*time.Second).Format(http.TimeFormat), now); !ok || d != 2*time.Second {
+		t.Fatalf("date: %v %v", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Fatal("expected invalid")
+	}
+}
+
+func TestRetryPolicy_BackoffCapped(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		if got := p.backoff(i+1, nil); got != w {
+			t.Fatalf("attempt %d: got %s want %s", i+1, got, w)
+		}
+	}
+	p.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		if got := p.backoff(1, nil); got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("jittered delay out of range: %s", got)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned when a Scheduler's queue is at MaxQueue.
+var ErrQueueFull = errors.New("ollama: scheduler queue full")
+
+// Priority orders calls waiting in a Scheduler; higher runs first and equal
+// priorities run in arrival order.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// WithPriority sets the scheduling priority of this call. Without it,
+// embedding calls run at PriorityLow and everything else at PriorityNormal.
+func WithPriority(p Priority) CallOption {
+	return func(cc *callConfig) { cc.priority = &p }
+}
+
+// SchedulerConfig configures a Scheduler. Zero limits are unlimited.
+type SchedulerConfig struct {
+	// MaxPerModel limits in-flight calls for one model on one host; match it
+	// to the server's OLLAMA_NUM_PARALLEL.
+	MaxPerModel int
+	// MaxPerHost limits in-flight calls to one host across all models.
+	MaxPerHost int
+	// MaxQueue limits how many calls may wait; further calls fail with
+	// ErrQueueFull.
+	MaxQueue int
+}
+
+// SchedulerStats is a snapshot of a Scheduler.
+type SchedulerStats struct {
+	InFlight int
+	Queued   int
+	// QueuedByModel breaks Queued down by model; calls without a model are
+	// under "".
+	QueuedByModel map[string]int
+	// Waited counts calls that had to queue and WaitTime is their total time
+	// in the queue; MaxWait is the longest single wait.
+	Waited   uint64
+	WaitTime time.Duration
+	MaxWait  time.Duration
+}
+
+// Scheduler limits in-flight calls per model and per host, queueing the
+// excess by priority. A call holds its slot until its response body or
+// stream is closed. Install it with WithScheduler.
+type Scheduler struct {
+	cfg SchedulerConfig
+
+	mu       sync.Mutex
+	byModel  map[BreakerKey]int
+	byHost   map[string]int
+	inflight int
+	queue    []*schedWaiter // sorted by priority, then arrival
+	waited   uint64
+	waitTime time.Duration
+	maxWait  time.Duration
+}
+
+type schedWaiter struct {
+	key     BreakerKey
+	prio    Priority
+	ready   chan struct{}
+	granted bool
+}
+
+// NewScheduler constructs a Scheduler.
+func NewScheduler(cfg SchedulerConfig) *Scheduler {
+	return &Scheduler{cfg: cfg, byModel: map[BreakerKey]int{}, byHost: map[string]int{}}
+}
+
+// WithScheduler routes every call through s. A Scheduler may be shared by
+// several clients talking to the same hosts.
+func WithScheduler(s *Scheduler) ClientOption {
+	return WithMiddleware(s.Middleware())
+}
+
+// Stats returns current queue depth and accumulated wait times.
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := SchedulerStats{
+		InFlight:      s.inflight,
+		Queued:        len(s.queue),
+		QueuedByModel: map[string]int{},
+		Waited:        s.waited,
+		WaitTime:      s.waitTime,
+		MaxWait:       s.maxWait,
+	}
+	for _, w := range s.queue {
+		st.QueuedByModel[w.key.Model]++
+	}
+	return st
+}
+
+// Middleware returns the scheduler as client middleware.
+func (s *Scheduler) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(ctx context.Context, req *Request) (*http.Response, error) {
+			key := BreakerKey{Host: req.Host, Model: req.Model}
+			if err := s.acquire(ctx, key, req.schedPriority()); err != nil {
+				return nil, err
+			}
+			resp, err := next(ctx, req)
+			if err != nil {
+				s.release(key)
+				return nil, err
+			}
+			resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { s.release(key) }}
+			return resp, nil
+		}
+	}
+}
+
+// schedPriority is the call's explicit priority or the endpoint default.
+func (r *Request) schedPriority() Priority {
+	if r.priority != nil {
+		return *r.priority
+	}
+	switch r.Path {
+	case "/api/embed", "/api/embeddings":
+		return PriorityLow
+	}
+	return PriorityNormal
+}
+
+func (s *Scheduler) acquire(ctx context.Context, key BreakerKey, prio Priority) error {
+	s.mu.Lock()
+	if s.fits(key) {
+		s.take(key)
+		s.mu.Unlock()
+		return nil
+	}
+	if s.cfg.MaxQueue > 0 && len(s.queue) >= s.cfg.MaxQueue {
+		s.mu.Unlock()
+		return ErrQueueFull
+	}
+	w := &schedWaiter{key: key, prio: prio, ready: make(chan struct{})}
+	i := sort.Search(len(s.queue), func(i int) bool { return s.queue[i].prio < prio })
+	s.queue = append(s.queue, nil)
+	copy(s.queue[i+1:], s.queue[i:])
+	s.queue[i] = w
+	s.mu.Unlock()
+
+	start := time.Now()
+	select {
+	case <-w.ready:
+		s.recordWait(time.Since(start))
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		if w.granted {
+			// the slot arrived as we gave up; pass it on
+			s.mu.Unlock()
+			s.release(key)
+			return ctx.Err()
+		}
+		s.remove(w)
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) recordWait(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waited++
+	s.waitTime += d
+	if d > s.maxWait {
+		s.maxWait = d
+	}
+}
+
+// release frees key's slot and grants waiters that now fit, in queue order.
+func (s *Scheduler) release(key BreakerKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight--
+	s.byHost[key.Host]--
+	if key.Model != "" {
+		s.byModel[key]--
+	}
+	for i := 0; i < len(s.queue); {
+		w := s.queue[i]
+		if !s.fits(w.key) {
+			i++
+			continue
+		}
+		s.take(w.key)
+		w.granted = true
+		close(w.ready)
+		s.queue = append(s.queue[:i], s.queue[i+1:]...)
+	}
+}
+
+func (s *Scheduler) fits(key BreakerKey) bool {
+	if s.cfg.MaxPerHost > 0 && s.byHost[key.Host] >= s.cfg.MaxPerHost {
+		return false
+	}
+	if s.cfg.MaxPerModel > 0 && key.Model != "" && s.byModel[key] >= s.cfg.MaxPerModel {
+		return false
+	}
+	return true
+}
+
+func (s *Scheduler) take(key BreakerKey) {
+	s.inflight++
+	s.byHost[key.Host]++
+	if key.Model != "" {
+		s.byModel[key]++
+	}
+}
+
+func (s *Scheduler) remove(w *schedWaiter) {
+	for i, q := range s.queue {
+		if q == w {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitQueued polls until s has n queued calls.
+func waitQueued(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Stats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued=%d, want %d", s.Stats().Queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduler_PriorityOrder(t *testing.T) {
+	gate := make(chan struct{})
+	var mu sync.Mutex
+	var order []string
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		order = append(order, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/api/generate" {
+			<-gate
+		}
+		_, _ = io.WriteString(w, `{"embeddings":[[1]],"message":{"role":"assistant","content":"x"},"response":"x"}`)
+	})
+	defer srv.Close()
+	s := NewScheduler(SchedulerConfig{MaxPerModel: 1})
+	WithScheduler(s)(c)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	run := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	run(func() error {
+		_, err := c.Generate(ctx, &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+		return err
+	})
+	for s.Stats().InFlight != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	run(func() error {
+		_, err := c.Embed(ctx, &EmbedRequest{Model: "m", Input: "x"})
+		return err
+	})
+	waitQueued(t, s, 1)
+	run(func() error {
+		_, err := c.Chat(ctx, &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+		return err
+	})
+	waitQueued(t, s, 2)
+	if got := s.Stats().QueuedByModel["m"]; got != 2 {
+		t.Fatalf("queued by model: %d", got)
+	}
+	close(gate)
+	wg.Wait()
+
+	if len(order) != 3 || order[1] != "/api/chat" || order[2] != "/api/embed" {
+		t.Fatalf("order: %v", order)
+	}
+	st := s.Stats()
+	if st.InFlight != 0 || st.Queued != 0 || st.Waited != 2 || st.WaitTime <= 0 || st.MaxWait <= 0 {
+		t.Fatalf("stats: %+v", st)
+	}
+}
+
+func TestScheduler_StreamHoldsSlotAndCancelWhileQueued(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"a\",\"done\":true}\n")
+	})
+	defer srv.Close()
+	s := NewScheduler(SchedulerConfig{MaxPerHost: 1, MaxQueue: 1})
+	WithScheduler(s)(c)
+
+	st, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.List(ctx)
+		errc <- err
+	}()
+	waitQueued(t, s, 1)
+	if _, err := c.PS(context.Background()); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	waitQueued(t, s, 0)
+
+	_ = st.Close()
+	if s.Stats().InFlight != 0 {
+		t.Fatalf("slot not released: %+v", s.Stats())
+	}
+	if _, err := c.List(context.Background(), WithPriority(PriorityHigh)); err != nil {
+		t.Fatal(err)
+	}
+}
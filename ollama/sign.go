@@ -0,0 +1,217 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithSigner signs requests with an Ed25519 key the way the Ollama CLI does
+// for ollama.com and private registries: the client sends
+// "METHOD,PATH?ts=UNIX" signed by the key as
+// "Authorization: <base64 public key>:<base64 signature>" and appends the ts
+// query parameter. Only the given paths are signed; by default /api/pull and
+// /api/push.
+func WithSigner(s crypto.Signer, paths ...string) ClientOption {
+	return func(c *Client) {
+		if len(paths) == 0 {
+			paths = []string{"/api/pull", "/api/push"}
+		}
+		c.signer = s
+		c.signPaths = paths
+	}
+}
+
+// LoadIdentity reads an Ed25519 private key in OpenSSH or PKCS#8 PEM form.
+// An empty path loads the Ollama identity at ~/.ollama/id_ed25519.
+// Encrypted keys are not supported.
+func LoadIdentity(path string) (crypto.Signer, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".ollama", "id_ed25519")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		return parseOpenSSHEd25519(block.Bytes)
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if pk, ok := k.(ed25519.PrivateKey); ok {
+			return pk, nil
+		}
+		return nil, fmt.Errorf("%s: not an ed25519 key", path)
+	}
+	return nil, fmt.Errorf("%s: unsupported key type %q", path, block.Type)
+}
+
+// AuthorizedKey returns the signer's public key in authorized_keys form
+// ("ssh-ed25519 AAAA..."), as registered with ollama.com.
+func AuthorizedKey(s crypto.Signer) (string, error) {
+	blob, err := sshPublicKeyBlob(s)
+	if err != nil {
+		return "", err
+	}
+	return "ssh-ed25519 " + base64.StdEncoding.EncodeToString(blob), nil
+}
+
+// sign is an inner middleware that adds the signed challenge to configured
+// paths. It runs per attempt so each retry carries a fresh timestamp.
+func (c *Client) sign(next RoundTripFunc) RoundTripFunc {
+	return func(ctx context.Context, req *Request) (*http.Response, error) {
+		if !c.signs(req.Path) {
+			return next(ctx, req)
+		}
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		sep := "?"
+		if strings.Contains(req.Path, "?") {
+			sep = "&"
+		}
+		r := *req
+		r.Path = req.Path + sep + "ts=" + ts
+		tok, err := signChallenge(c.signer, fmt.Sprintf("%s,%s", req.Method, r.Path))
+		if err != nil {
+			return nil, err
+		}
+		r.Header = req.Header.Clone()
+		if r.Header == nil {
+			r.Header = http.Header{}
+		}
+		r.Header.Set("Authorization", tok)
+		return next(ctx, &r)
+	}
+}
+
+func (c *Client) signs(path string) bool {
+	p, _, _ := strings.Cut(path, "?")
+	for _, sp := range c.signPaths {
+		if p == sp {
+			return true
+		}
+	}
+	return false
+}
+
+// signChallenge returns "<base64 ssh public key>:<base64 signature>".
+func signChallenge(s crypto.Signer, challenge string) (string, error) {
+	blob, err := sshPublicKeyBlob(s)
+	if err != nil {
+		return "", err
+	}
+	sig, err := s.Sign(rand.Reader, []byte(challenge), crypto.Hash(0))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(blob) + ":" + base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// sshPublicKeyBlob encodes an Ed25519 public key in SSH wire format.
+func sshPublicKeyBlob(s crypto.Signer) ([]byte, error) {
+	pub, ok := s.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("ollama: signer must hold an ed25519 key")
+	}
+	var b bytes.Buffer
+	writeSSHString(&b, []byte("ssh-ed25519"))
+	writeSSHString(&b, pub)
+	return b.Bytes(), nil
+}
+
+func writeSSHString(b *bytes.Buffer, s []byte) {
+	_ = binary.Write(b, binary.BigEndian, uint32(len(s)))
+	b.Write(s)
+}
+
+// sshReader consumes SSH wire-format fields.
+type sshReader struct {
+	b   []byte
+	err error
+}
+
+func (r *sshReader) uint32() uint32 {
+	if r.err != nil || len(r.b) < 4 {
+		r.err = errMalformedKey
+		return 0
+	}
+	v := binary.BigEndian.Uint32(r.b)
+	r.b = r.b[4:]
+	return v
+}
+
+func (r *sshReader) string() []byte {
+	n := r.uint32()
+	if r.err != nil || uint32(len(r.b)) < n {
+		r.err = errMalformedKey
+		return nil
+	}
+	s := r.b[:n]
+	r.b = r.b[n:]
+	return s
+}
+
+var errMalformedKey = errors.New("ollama: malformed openssh private key")
+
+// parseOpenSSHEd25519 decodes an unencrypted "openssh-key-v1" private key.
+func parseOpenSSHEd25519(data []byte) (ed25519.PrivateKey, error) {
+	const magic = "openssh-key-v1\x00"
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return nil, errMalformedKey
+	}
+	r := &sshReader{b: data[len(magic):]}
+	cipher := string(r.string())
+	_ = r.string() // kdf name
+	_ = r.string() // kdf options
+	if n := r.uint32(); r.err == nil && n != 1 {
+		return nil, fmt.Errorf("ollama: expected 1 key, found %d", n)
+	}
+	_ = r.string() // public key
+	priv := &sshReader{b: r.string()}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if cipher != "none" {
+		return nil, errors.New("ollama: encrypted private keys are not supported")
+	}
+	check1, check2 := priv.uint32(), priv.uint32()
+	if check1 != check2 {
+		return nil, errMalformedKey
+	}
+	if typ := string(priv.string()); priv.err == nil && typ != "ssh-ed25519" {
+		return nil, fmt.Errorf("ollama: unsupported key type %q", typ)
+	}
+	_ = priv.string() // public key
+	key := priv.string()
+	if priv.err != nil {
+		return nil, priv.err
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errMalformedKey
+	}
+	return ed25519.PrivateKey(bytes.Clone(key)), nil
+}
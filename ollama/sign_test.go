@@ -0,0 +1,127 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// marshalOpenSSH encodes key as an unencrypted openssh-key-v1 PEM block.
+func marshalOpenSSH(key ed25519.PrivateKey) []byte {
+	pub := key.Public().(ed25519.PublicKey)
+	var pubBlob bytes.Buffer
+	writeSSHString(&pubBlob, []byte("ssh-ed25519"))
+	writeSSHString(&pubBlob, pub)
+
+	var priv bytes.Buffer
+	_ = binary.Write(&priv, binary.BigEndian, uint32(0x12345678))
+	_ = binary.Write(&priv, binary.BigEndian, uint32(0x12345678))
+	writeSSHString(&priv, []byte("ssh-ed25519"))
+	writeSSHString(&priv, pub)
+	writeSSHString(&priv, key)
+	writeSSHString(&priv, []byte("test@host"))
+	for i := byte(1); priv.Len()%8 != 0; i++ {
+		priv.WriteByte(i)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("openssh-key-v1\x00")
+	writeSSHString(&b, []byte("none"))
+	writeSSHString(&b, []byte("none"))
+	writeSSHString(&b, nil)
+	_ = binary.Write(&b, binary.BigEndian, uint32(1))
+	writeSSHString(&b, pubBlob.Bytes())
+	writeSSHString(&b, priv.Bytes())
+	return pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: b.Bytes()})
+}
+
+func TestLoadIdentity_OpenSSH(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, marshalOpenSSH(key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadIdentity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.(ed25519.PrivateKey), key) {
+		t.Fatal("loaded key differs")
+	}
+	ak, err := AuthorizedKey(s)
+	if err != nil || !strings.HasPrefix(ak, "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5") {
+		t.Fatalf("authorized key=%q err=%v", ak, err)
+	}
+}
+
+func TestLoadIdentity_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	_ = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: []byte("openssh-key-v1\x00\x00")}), 0o600)
+	if _, err := LoadIdentity(path); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// verifySignedRequest checks the Authorization header the way a registry
+// stand-in would: decode the SSH public key, rebuild the challenge and verify.
+func verifySignedRequest(r *http.Request) bool {
+	pubPart, sigPart, ok := strings.Cut(r.Header.Get("Authorization"), ":")
+	if !ok || r.URL.Query().Get("ts") == "" {
+		return false
+	}
+	blob, err1 := base64.StdEncoding.DecodeString(pubPart)
+	sig, err2 := base64.StdEncoding.DecodeString(sigPart)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	rd := &sshReader{b: blob}
+	if string(rd.string()) != "ssh-ed25519" {
+		return false
+	}
+	pub := rd.string()
+	if rd.err != nil || len(pub) != ed25519.PublicKeySize {
+		return false
+	}
+	challenge := r.Method + "," + r.URL.RequestURI()
+	return ed25519.Verify(ed25519.PublicKey(pub), []byte(challenge), sig)
+}
+
+func TestWithSigner_SignsPullAndPush(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signed := map[string]bool{}
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		signed[r.URL.Path] = verifySignedRequest(r)
+		_, _ = io.WriteString(w, `{"status":"success","response":"x"}`)
+	})
+	defer srv.Close()
+	WithSigner(key)(c)
+	ctx := context.Background()
+	if _, err := c.Pull(ctx, &PullRequest{Model: "m"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Push(ctx, &PushRequest{Model: "me/m"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Generate(ctx, &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !signed["/api/pull"] || !signed["/api/push"] {
+		t.Fatalf("signature not verified: %v", signed)
+	}
+	if signed["/api/generate"] {
+		t.Fatal("generate should not be signed by default")
+	}
+}
@@ -0,0 +1,222 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SSEOptions configures ServeSSE. Empty names and a zero Heartbeat take the
+// defaults.
+type SSEOptions struct {
+	// Event names for chat and generate streams. Defaults: "thinking",
+	// "content", "tool_call", "done" and "error".
+	ThinkingEvent string
+	ContentEvent  string
+	ToolCallEvent string
+	DoneEvent     string
+	ErrorEvent    string
+	// ChunkEvent names events for other stream types, whose chunks are sent
+	// whole. Default "message".
+	ChunkEvent string
+	// Heartbeat is how long the connection may stay silent, e.g. while the
+	// model loads, before a ": ping" comment is sent. Default 15 seconds;
+	// negative disables heartbeats.
+	Heartbeat time.Duration
+}
+
+func (o *SSEOptions) defaults() {
+	for _, f := range []struct {
+		p   *string
+		def string
+	}{
+		{&o.ThinkingEvent, "thinking"},
+		{&o.ContentEvent, "content"},
+		{&o.ToolCallEvent, "tool_call"},
+		{&o.DoneEvent, "done"},
+		{&o.ErrorEvent, "error"},
+		{&o.ChunkEvent, "message"},
+	} {
+		if *f.p == "" {
+			*f.p = f.def
+		}
+	}
+	if o.Heartbeat == 0 {
+		o.Heartbeat = 15 * time.Second
+	}
+}
+
+// sseText is the payload of thinking and content events.
+type sseText struct {
+	Text string `json:"text"`
+}
+
+type sseItem struct {
+	name string
+	data any
+	err  error
+}
+
+// ServeSSE writes s to w as Server-Sent Events and closes s when done. Chat
+// and generate streams are split into thinking and content events carrying
+// {"text": ...}, tool_call events carrying the ToolCall, and a done event
+// carrying the final metrics; other streams send each chunk as JSON. Every
+// event is flushed as it is written.
+//
+// If the client behind r disconnects, s is closed so the model stops
+// generating, and ServeSSE returns the request context's error. A stream
+// error is sent as an error event carrying {"error": ...} and returned.
+// ServeSSE returns nil when the stream ends normally.
+func ServeSSE[T any](w http.ResponseWriter, r *http.Request, s *Stream[T], opts SSEOptions) error {
+	return ServeSSEFunc(w, r, func(context.Context) (*Stream[T], error) { return s, nil }, opts)
+}
+
+// ServeSSEFunc is like ServeSSE but opens the stream itself with the request
+// context, sending heartbeats while open runs. Ollama answers only once the
+// model is loaded, so this keeps the browser connection alive through long
+// loads. An error from open is sent as an error event and returned.
+func ServeSSEFunc[T any](w http.ResponseWriter, r *http.Request, open func(ctx context.Context) (*Stream[T], error), opts SSEOptions) error {
+	opts.defaults()
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	_ = rc.Flush()
+
+	var mu sync.Mutex
+	var stream *Stream[T]
+	closed := false
+	closeStream := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		closed = true
+		if stream != nil {
+			_ = stream.Close()
+		}
+	}
+	defer closeStream()
+
+	items := make(chan sseItem)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(items)
+		s, err := open(r.Context())
+		var next func() sseItem
+		if err == nil {
+			mu.Lock()
+			if closed {
+				// the handler returned while open ran
+				mu.Unlock()
+				_ = s.Close()
+				return
+			}
+			stream = s
+			mu.Unlock()
+			next = sseSource(s, &opts)
+		} else {
+			next = func() sseItem { return sseItem{err: err} }
+		}
+		for {
+			it := next()
+			select {
+			case items <- it:
+			case <-stop:
+				return
+			}
+			if it.err != nil {
+				return
+			}
+		}
+	}()
+
+	var heartbeat <-chan time.Time
+	var timer *time.Timer
+	if opts.Heartbeat > 0 {
+		timer = time.NewTimer(opts.Heartbeat)
+		defer timer.Stop()
+		heartbeat = timer.C
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			closeStream()
+			return r.Context().Err()
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return err
+			}
+			_ = rc.Flush()
+			timer.Reset(opts.Heartbeat)
+		case it := <-items:
+			if it.err == EOF {
+				return nil
+			}
+			if it.err != nil {
+				_ = writeSSE(w, opts.ErrorEvent, map[string]string{"error": it.err.Error()})
+				_ = rc.Flush()
+				return it.err
+			}
+			if err := writeSSE(w, it.name, it.data); err != nil {
+				return err
+			}
+			_ = rc.Flush()
+			if timer != nil {
+				timer.Reset(opts.Heartbeat)
+			}
+		}
+	}
+}
+
+// sseSource yields the named events for s.
+func sseSource[T any](s *Stream[T], o *SSEOptions) func() sseItem {
+	var es *EventStream
+	switch v := any(s).(type) {
+	case *Stream[ChatResponse]:
+		es = ChatEvents(v)
+	case *Stream[GenerateResponse]:
+		es = GenerateEvents(v)
+	default:
+		return func() sseItem {
+			c, err := s.Recv()
+			return sseItem{name: o.ChunkEvent, data: c, err: err}
+		}
+	}
+	return func() sseItem {
+		ev, err := es.Recv()
+		if err != nil {
+			return sseItem{err: err}
+		}
+		switch ev := ev.(type) {
+		case ThinkingDelta:
+			return sseItem{name: o.ThinkingEvent, data: sseText{Text: ev.Text}}
+		case ContentDelta:
+			return sseItem{name: o.ContentEvent, data: sseText{Text: ev.Text}}
+		case ToolCallEvent:
+			return sseItem{name: o.ToolCallEvent, data: ev.Call}
+		case Done:
+			if ev.Context != nil {
+				return sseItem{name: o.DoneEvent, data: struct {
+					BaseGenerateResponse
+					Context []int `json:"context"`
+				}{ev.Metrics, ev.Context}}
+			}
+			return sseItem{name: o.DoneEvent, data: ev.Metrics}
+		}
+		return sseItem{err: fmt.Errorf("ollama: unexpected event %T", ev)}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, event string, data any) error {
+	b, err := encodeJSON(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	return err
+}
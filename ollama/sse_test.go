@@ -0,0 +1,135 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseProxy serves ServeSSEFunc over a chat stream from c.
+func sseProxy(t *testing.T, c *Client, opts SSEOptions, result chan<- error) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := ServeSSEFunc(w, r, func(ctx context.Context) (*Stream[ChatResponse], error) {
+			return c.ChatStream(ctx, &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+		}, opts)
+		if result != nil {
+			result <- err
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServeSSE_ChatEventsAndHeartbeat(t *testing.T) {
+	up, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(60 * time.Millisecond) // model load
+		_, _ = io.WriteString(w, reasoningChat)
+	})
+	defer up.Close()
+	proxy := sseProxy(t, c, SSEOptions{ContentEvent: "delta", Heartbeat: 20 * time.Millisecond}, nil)
+
+	resp, err := http.Get(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %q", ct)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	out := string(b)
+	if !strings.HasPrefix(out, ": ping\n\n") {
+		t.Fatalf("no heartbeat before first event:\n%s", out)
+	}
+	for _, want := range []string{
+		"event: thinking\ndata: {\"text\":\"hmm\"}\n\n",
+		"event: delta\ndata: {\"text\":\"Hi\"}\n\n",
+		"event: tool_call\ndata: {\"function\":{\"name\":\"lookup\",\"arguments\":{\"q\":\"x\"}}}\n\n",
+		"event: done\ndata: {\"done\":true,\"done_reason\":\"stop\",\"eval_count\":3}\n\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestServeSSE_ClientDisconnectClosesUpstream(t *testing.T) {
+	upstreamDone := make(chan struct{})
+	up, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		defer close(upstreamDone)
+		for {
+			_, _ = io.WriteString(w, "{\"message\":{\"role\":\"assistant\",\"content\":\"tok\"}}\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+	defer up.Close()
+	result := make(chan error, 1)
+	proxy := sseProxy(t, c, SSEOptions{}, result)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, proxy.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if line != "event: content\n" {
+		t.Fatalf("first line %q", line)
+	}
+	cancel()
+	_ = resp.Body.Close()
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Fatal("expected context error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ServeSSE did not return")
+	}
+	select {
+	case <-upstreamDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("upstream stream not closed")
+	}
+}
+
+func TestServeSSE_StreamErrorEvent(t *testing.T) {
+	up, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"error\":\"runner crashed\"}\n")
+	})
+	defer up.Close()
+	result := make(chan error, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := c.ChatStream(r.Context(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		result <- ServeSSE(w, r, s, SSEOptions{Heartbeat: -1})
+	}))
+	defer proxy.Close()
+	resp, err := http.Get(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(b) != "event: error\ndata: {\"error\":\"runner crashed (status code: 200)\"}\n\n" {
+		t.Fatalf("body %q", b)
+	}
+	if err := <-result; err == nil {
+		t.Fatal("expected error")
+	}
+}
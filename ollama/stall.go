@@ -0,0 +1,162 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrStreamStalled is matched by errors returned when a stream produces no
+// chunk within its idle timeout.
+var ErrStreamStalled = errors.New("ollama: stream stalled")
+
+// StreamStalledError reports which idle timeout a stream exceeded.
+type StreamStalledError struct {
+	// FirstChunk is true when no chunk had arrived yet.
+	FirstChunk bool
+	After      time.Duration
+}
+
+func (e *StreamStalledError) Error() string {
+	if e.FirstChunk {
+		return fmt.Sprintf("ollama: stream stalled: no first chunk after %s", e.After)
+	}
+	return fmt.Sprintf("ollama: stream stalled: no chunk for %s", e.After)
+}
+
+// Is reports whether target is ErrStreamStalled.
+func (e *StreamStalledError) Is(target error) bool { return target == ErrStreamStalled }
+
+// WithStreamIdleTimeout aborts streams whose server goes quiet: firstChunk
+// bounds the wait from sending the request to the first bytes of the body,
+// which includes loading the model, and betweenChunks bounds every later
+// wait for more data. Only time spent waiting on the server counts: not
+// queueing in a Scheduler, retry backoff, auto-pulls or a slow consumer
+// between Recv calls. Recv then returns a *StreamStalledError. Zero
+// disables either timeout.
+func WithStreamIdleTimeout(firstChunk, betweenChunks time.Duration) ClientOption {
+	return func(c *Client) { c.firstChunkTimeout, c.chunkTimeout = firstChunk, betweenChunks }
+}
+
+// WithCallIdleTimeout overrides WithStreamIdleTimeout for this call.
+func WithCallIdleTimeout(firstChunk, betweenChunks time.Duration) CallOption {
+	return func(cc *callConfig) { cc.idle = &[2]time.Duration{firstChunk, betweenChunks} }
+}
+
+// stallTimer cancels a stream's context when the server is overdue. It runs
+// only while the client waits: from arm, when the request is sent, until
+// pause once headers arrive, and then around each body read. A nil
+// *stallTimer does nothing.
+type stallTimer struct {
+	cancel         context.CancelCauseFunc
+	first, between time.Duration
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	started  time.Time     // when timer was started
+	left     time.Duration // first-chunk budget not yet spent waiting
+	received bool          // body bytes have arrived
+}
+
+// startStallTimer returns a context that is cancelled with a
+// *StreamStalledError when the stream stalls, or ctx unchanged when both
+// timeouts are zero. The timer starts at arm.
+func startStallTimer(ctx context.Context, first, between time.Duration) (context.Context, *stallTimer) {
+	if first <= 0 && between <= 0 {
+		return ctx, nil
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	return ctx, &stallTimer{cancel: cancel, first: first, between: between}
+}
+
+// arm starts the first-chunk timeout as the request is sent.
+func (t *stallTimer) arm() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.left = t.first
+	t.startLocked()
+}
+
+// pause stops the timer while the client is not waiting on the server.
+func (t *stallTimer) pause() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopLocked()
+}
+
+// startLocked runs the timeout that applies now: what is left of the
+// first-chunk budget, or the full between-chunks one.
+func (t *stallTimer) startLocked() {
+	t.stopLocked()
+	t.started = time.Now()
+	if !t.received {
+		if t.first > 0 {
+			after := t.first
+			t.timer = time.AfterFunc(t.left, func() { t.cancel(&StreamStalledError{FirstChunk: true, After: after}) })
+		}
+		return
+	}
+	if t.between > 0 {
+		after := t.between
+		t.timer = time.AfterFunc(after, func() { t.cancel(&StreamStalledError{After: after}) })
+	}
+}
+
+func (t *stallTimer) stopLocked() {
+	if t.timer == nil {
+		return
+	}
+	t.timer.Stop()
+	t.timer = nil
+	if !t.received {
+		t.left -= time.Since(t.started)
+	}
+}
+
+// stop releases the timer and the context.
+func (t *stallTimer) stop() {
+	if t == nil {
+		return
+	}
+	t.pause()
+	t.cancel(nil)
+}
+
+// stallBody times each read of a stream body.
+type stallBody struct {
+	io.ReadCloser
+	t *stallTimer
+}
+
+func (b *stallBody) Read(p []byte) (int, error) {
+	t := b.t
+	t.mu.Lock()
+	t.startLocked()
+	t.mu.Unlock()
+	n, err := b.ReadCloser.Read(p)
+	t.mu.Lock()
+	t.stopLocked()
+	if n > 0 {
+		t.received = true
+	}
+	t.mu.Unlock()
+	return n, err
+}
+
+// stalled returns the *StreamStalledError that cancelled ctx, if any.
+func stalled(ctx context.Context) error {
+	var se *StreamStalledError
+	if errors.As(context.Cause(ctx), &se) {
+		return se
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStreamIdleTimeout_FirstChunk(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	defer srv.Close()
+	start := time.Now()
+	_, err := c.ChatStream(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}, WithCallIdleTimeout(50*time.Millisecond, 0))
+	var se *StreamStalledError
+	if !errors.Is(err, ErrStreamStalled) || !errors.As(err, &se) || !se.FirstChunk {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("took %s", time.Since(start))
+	}
+}
+
+func TestStreamIdleTimeout_BetweenChunks(t *testing.T) {
+	aborted := make(chan struct{})
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		for i := 0; i < 3; i++ {
+			_, _ = io.WriteString(w, "{\"response\":\"a\"}\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+		select {
+		case <-r.Context().Done():
+			close(aborted)
+		case <-time.After(2 * time.Second):
+		}
+	})
+	defer srv.Close()
+	WithStreamIdleTimeout(time.Second, 80*time.Millisecond)(c)
+
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	for i := 0; i < 3; i++ {
+		if _, err := s.Recv(); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+	}
+	_, err = s.Recv()
+	var se *StreamStalledError
+	if !errors.As(err, &se) || se.FirstChunk || se.After != 80*time.Millisecond {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("server request not aborted")
+	}
+}
+
+func TestStreamIdleTimeout_SlowConsumer(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// the server is prompt; only the consumer is slow
+		for i := 0; i < 8; i++ {
+			_, _ = io.WriteString(w, "{\"response\":\"a\"}\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(15 * time.Millisecond)
+		}
+		_, _ = io.WriteString(w, "{\"response\":\"\",\"done\":true}\n")
+	})
+	defer srv.Close()
+	WithStreamIdleTimeout(40*time.Millisecond, 40*time.Millisecond)(c)
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	for {
+		time.Sleep(80 * time.Millisecond)
+		_, err := s.Recv()
+		if err == EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStreamIdleTimeout_ExcludesSchedulerQueue(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"a\",\"done\":true}\n")
+	})
+	defer srv.Close()
+	WithScheduler(NewScheduler(SchedulerConfig{MaxPerModel: 1}))(c)
+	WithStreamIdleTimeout(100*time.Millisecond, 0)(c)
+	req := &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}}
+	held, err := c.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_ = held.Close()
+	}()
+	s, err := c.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	if _, err := s.Collect(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStreamIdleTimeout_MidLine(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"respo`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	defer srv.Close()
+	WithStreamIdleTimeout(time.Second, 100*time.Millisecond)(c)
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	_, err = s.Recv()
+	var se *StreamStalledError
+	if !errors.As(err, &se) || se.FirstChunk {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
@@ -2,8 +2,11 @@ package ollama
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 )
@@ -20,49 +23,250 @@ type Stream[T any] struct {
 	resp   *http.Response
 	rd     *bufio.Reader
 	closer io.Closer
+	cancel context.CancelFunc
+	trace  *callTrace
 	decode func([]byte, *T) error
+	next   func() (*T, error) // replaces reading rd, for Tee consumers
+
+	buf     []byte // assembles lines longer than rd's buffer
+	maxLine int
+	err     error // sticky error, e.g. a line over maxLine
+
+	// completion tracking for chat, generate and progress streams
+	observe func(*T) bool // records a chunk, reports whether it completes the stream
+	partial func() any
+	chunks  int
+	done    bool
 }
 
 func newStream[T any](ctx context.Context, resp *http.Response) *Stream[T] {
-	return &Stream[T]{
-		ctx:    ctx,
-		resp:   resp,
-		rd:     bufio.NewReader(resp.Body),
-		closer: resp.Body,
-		decode: func(b []byte, v *T) error { return json.Unmarshal(b, v) },
+	s := &Stream[T]{
+		ctx:     ctx,
+		resp:    resp,
+		rd:      bufio.NewReader(resp.Body),
+		closer:  resp.Body,
+		decode:  func(b []byte, v *T) error { return json.Unmarshal(b, v) },
+		maxLine: defaultMaxLine,
 	}
+	s.trackCompletion()
+	return s
+}
+
+// ErrIncompleteStream is matched by errors returned when a stream's body
+// ends or its connection drops before the final chunk: done=true for chat and
+// generate streams, or a "success" status for progress streams. A line cut
+// off by the drop is discarded.
+var ErrIncompleteStream = errors.New("ollama: incomplete stream")
+
+// IncompleteStreamError reports a stream cut short, for example by a dropped
+// connection or a crashed runner.
+type IncompleteStreamError struct {
+	// Chunks is the number of chunks received.
+	Chunks int
+	// Partial is the output received before the cut: the merged
+	// *ChatResponse or *GenerateResponse, or the last *ProgressResponse (nil
+	// if none arrived).
+	Partial any
+}
+
+func (e *IncompleteStreamError) Error() string {
+	if _, ok := e.Partial.(*ProgressResponse); ok || e.Partial == nil {
+		return fmt.Sprintf("ollama: stream ended without success status (%d chunks)", e.Chunks)
+	}
+	return fmt.Sprintf("ollama: stream ended without done (%d chunks)", e.Chunks)
+}
+
+// Is reports whether target is ErrIncompleteStream.
+func (e *IncompleteStreamError) Is(target error) bool { return target == ErrIncompleteStream }
+
+// trackCompletion sets up completion checks for the stream types that have
+// a final chunk.
+func (s *Stream[T]) trackCompletion() {
+	switch any(s).(type) {
+	case *Stream[ChatResponse]:
+		var a ChatAccumulator
+		s.observe = func(v *T) bool {
+			c := any(v).(*ChatResponse)
+			a.Add(c)
+			return c.Done != nil && *c.Done
+		}
+		s.partial = func() any { return a.Response() }
+	case *Stream[GenerateResponse]:
+		var a GenerateAccumulator
+		s.observe = func(v *T) bool {
+			g := any(v).(*GenerateResponse)
+			a.Add(g)
+			return g.Done != nil && *g.Done
+		}
+		s.partial = func() any { return a.Response() }
+	case *Stream[ProgressResponse]:
+		var last *ProgressResponse
+		s.observe = func(v *T) bool {
+			last = any(v).(*ProgressResponse)
+			return last.Status != nil && *last.Status == "success"
+		}
+		s.partial = func() any {
+			if last == nil {
+				return nil
+			}
+			return last
+		}
+	}
+}
+
+// endErr is the error for a body that ended: io.EOF, or an
+// *IncompleteStreamError when the stream's final chunk never arrived.
+func (s *Stream[T]) endErr() error {
+	if s.observe == nil || s.done {
+		return io.EOF
+	}
+	return &IncompleteStreamError{Chunks: s.chunks, Partial: s.partial()}
 }
 
 // Recv reads next JSON line chunk.
-// Recv reads and decodes the next JSON line from the stream.
+// Recv reads and decodes the next JSON line from the stream. Each line is
+// decoded once; a line carrying a top-level "error" field is returned as a
+// *ResponseError. Blank lines are skipped.
 func (s *Stream[T]) Recv() (*T, error) {
+	if s.next != nil {
+		return s.next()
+	}
 	if s.rd == nil {
 		return nil, io.EOF
 	}
-	line, err := s.rd.ReadBytes('\n')
-	if len(line) == 0 && err != nil {
-		if err == io.EOF {
-			return nil, io.EOF
+	if s.err != nil {
+		return nil, s.err
+	}
+	var line []byte
+	var err error
+	for {
+		line, err = s.readLine()
+		line = bytesTrimSpace(line)
+		if len(line) > 0 || err != nil {
+			break
 		}
+	}
+	if errors.Is(err, ErrLineTooLong) {
+		s.err = err
+		s.trace.fail(err)
 		return nil, err
 	}
-	var out T
-	if err := s.decode(bytesTrimSpace(line), &out); err != nil {
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		// The body failed or ended; a fragment read before a failure, such
+		// as a dropped connection or a stall mid-line, is never decoded.
+		if serr := stalled(s.ctx); serr != nil {
+			err = serr
+		} else if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = s.endErr()
+		}
+		if err != io.EOF {
+			s.trace.fail(err)
+		}
 		return nil, err
 	}
 
-	// If the JSON has an "error" field, mirror Python behavior and return ResponseError
-	var probe map[string]any
-	if err := json.Unmarshal(bytesTrimSpace(line), &probe); err == nil {
-		if e, ok := probe["error"].(string); ok && e != "" {
-			return nil, &ResponseError{Message: e, StatusCode: s.resp.StatusCode}
+	// If the JSON has an "error" field, mirror Python behavior and return
+	// ResponseError. The byte scan keeps ordinary chunks to one decode.
+	if hasErrorKey(line) {
+		var probe struct {
+			Error any `json:"error"`
+		}
+		if json.Unmarshal(line, &probe) == nil {
+			if e, ok := probe.Error.(string); ok && e != "" {
+				err := &ResponseError{Message: e, StatusCode: s.resp.StatusCode}
+				s.trace.fail(err)
+				return nil, err
+			}
+		}
+	}
+	out := new(T)
+	if derr := s.decode(line, out); derr != nil {
+		if err == io.EOF {
+			// an unterminated last line that does not decode was cut short
+			if eerr := s.endErr(); eerr != io.EOF {
+				derr = eerr
+			}
+		}
+		s.trace.fail(derr)
+		return nil, derr
+	}
+	s.trace.chunk(out)
+	s.chunks++
+	if s.observe != nil && s.observe(out) {
+		s.done = true
+	}
+	return out, nil
+}
+
+// ErrLineTooLong is matched by the error Recv returns for a line longer
+// than the stream's limit (see WithMaxStreamLine). The stream cannot be read
+// further.
+var ErrLineTooLong = errors.New("ollama: stream line too long")
+
+// defaultMaxLine bounds a single NDJSON line. Final generate chunks carry
+// the whole context array, so this is generous.
+const defaultMaxLine = 16 << 20
+
+// WithMaxStreamLine sets the longest NDJSON line a stream accepts, in bytes.
+// Longer lines fail with ErrLineTooLong instead of growing memory without
+// bound. The default is 16 MiB.
+func WithMaxStreamLine(n int) ClientOption {
+	return func(c *Client) { c.maxLine = n }
+}
+
+// readLine returns the next line including its newline. Lines that fit the
+// reader's buffer are returned without copying; longer ones are assembled
+// in s.buf, which is reused. The result is valid until the next call.
+func (s *Stream[T]) readLine() ([]byte, error) {
+	s.buf = s.buf[:0]
+	for {
+		frag, err := s.rd.ReadSlice('\n')
+		n := len(s.buf) + len(frag)
+		if n > 0 && err == nil {
+			n-- // the newline
+		}
+		if n > s.maxLine {
+			return nil, fmt.Errorf("%w (limit %d bytes)", ErrLineTooLong, s.maxLine)
+		}
+		if err == bufio.ErrBufferFull {
+			s.buf = append(s.buf, frag...)
+			continue
+		}
+		if len(s.buf) == 0 {
+			return frag, err
+		}
+		s.buf = append(s.buf, frag...)
+		return s.buf, err
+	}
+}
+
+var errorKey = []byte(`"error"`)
+
+// hasErrorKey reports whether line may contain an "error" key. Inside JSON
+// strings quotes are escaped, so a match is a key somewhere in the object;
+// Recv then decodes just that field to check it is top-level.
+func hasErrorKey(line []byte) bool {
+	for {
+		i := bytes.Index(line, errorKey)
+		if i < 0 {
+			return false
+		}
+		line = line[i+len(errorKey):]
+		j := 0
+		for j < len(line) && (line[j] == ' ' || line[j] == '\t' || line[j] == '\r' || line[j] == '\n') {
+			j++
+		}
+		if j < len(line) && line[j] == ':' {
+			return true
 		}
 	}
-	return &out, nil
 }
 
 // Close releases the underlying response body.
 func (s *Stream[T]) Close() error {
+	if s.cancel != nil {
+		defer s.cancel()
+	}
 	if s.closer == nil {
 		return nil
 	}
@@ -0,0 +1,41 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// benchStream returns a stream over n copies of line followed by final.
+func benchStream[T any](n int, line, final string) *Stream[T] {
+	body := strings.Repeat(line+"\n", n) + final + "\n"
+	resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(body)))}
+	return newStream[T](context.Background(), resp)
+}
+
+func benchmarkRecv[T any](b *testing.B, line, final string) {
+	s := benchStream[T](b.N, line, final)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(line) + 1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Recv(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamRecv_Generate(b *testing.B) {
+	benchmarkRecv[GenerateResponse](b,
+		`{"model":"llama3.2","created_at":"2025-08-14T10:00:00.000000Z","response":" token","done":false}`,
+		`{"model":"llama3.2","response":"","done":true,"done_reason":"stop","eval_count":10}`)
+}
+
+func BenchmarkStreamRecv_Chat(b *testing.B) {
+	benchmarkRecv[ChatResponse](b,
+		`{"model":"llama3.2","created_at":"2025-08-14T10:00:00.000000Z","message":{"role":"assistant","content":" token"},"done":false}`,
+		`{"model":"llama3.2","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop"}`)
+}
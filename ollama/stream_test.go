@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -76,3 +79,171 @@ func TestStream_RequestBodyIncludesStreamTrue(t *testing.T) {
 		t.Fatalf("expected stream=true, got: %v", got["stream"])
 	}
 }
+
+func TestStream_IncompleteGenerateCarriesPartial(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"The quick\"}\n{\"response\":\" brown\"}\n")
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	for i := 0; i < 2; i++ {
+		if _, err := s.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = s.Recv()
+	var ie *IncompleteStreamError
+	if !errors.Is(err, ErrIncompleteStream) || !errors.As(err, &ie) || ie.Chunks != 2 {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if p := ie.Partial.(*GenerateResponse); p.Response != "The quick brown" {
+		t.Fatalf("partial: %+v", p)
+	}
+}
+
+func TestStream_CompleteChatEndsWithEOF(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"message\":{\"role\":\"assistant\",\"content\":\"hi\"}}\n{\"message\":{\"role\":\"assistant\",\"content\":\"\"},\"done\":true}\n")
+	})
+	defer srv.Close()
+	s, err := c.ChatStream(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	for i := 0; i < 2; i++ {
+		if _, err := s.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Recv(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestStream_ProgressWithoutSuccess(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"status\":\"pulling manifest\"}\n{\"status\":\"downloading\",\"completed\":1,\"total\":2}\n")
+	})
+	defer srv.Close()
+	s, err := c.PullStream(context.Background(), &PullRequest{Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Collect()
+	var ie *IncompleteStreamError
+	if !errors.As(err, &ie) || *ie.Partial.(*ProgressResponse).Status != "downloading" {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestStream_LongLinesAndLimit(t *testing.T) {
+	long := strings.Repeat("x", 10000) // longer than the reader's buffer
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\""+long+"\"}\n\n{\"response\":\"\",\"done\":true}\n")
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := s.Collect()
+	if err != nil || out.Response != long {
+		t.Fatalf("len=%d err=%v", len(out.Response), err)
+	}
+
+	WithMaxStreamLine(5000)(c)
+	s, err = c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	if _, err := s.Recv(); !errors.Is(err, ErrLineTooLong) || !strings.Contains(err.Error(), "5000") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if _, err := s.Recv(); !errors.Is(err, ErrLineTooLong) {
+		t.Fatalf("error not sticky: %v", err)
+	}
+}
+
+func TestStream_ErrorFieldDetection(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// "error" inside content and nested keys is not a stream error
+		_, _ = io.WriteString(w, `{"message":{"role":"assistant","content":"an \"error\": here"}}
+{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"f","arguments":{"error":"x"}}}]}}
+{"error" : "boom"}
+`)
+	})
+	defer srv.Close()
+	s, err := c.ChatStream(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	for i := 0; i < 2; i++ {
+		if _, err := s.Recv(); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+	}
+	var re *ResponseError
+	if _, err := s.Recv(); !errors.As(err, &re) || re.Message != "boom" {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+// cutServer sends body as one chunk of a chunked response, then drops the
+// connection without the terminating chunk.
+func cutServer(t *testing.T, body string) (*httptest.Server, *Client) {
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nContent-Type: application/x-ndjson\r\nTransfer-Encoding: chunked\r\n\r\n%x\r\n%s\r\n", len(body), body)
+		_ = buf.Flush()
+	})
+}
+
+func TestStream_DroppedConnectionIsIncomplete(t *testing.T) {
+	for name, body := range map[string]string{
+		"line boundary": "{\"response\":\"a\"}\n",
+		"mid-line":      "{\"response\":\"a\"}\n{\"respo",
+	} {
+		srv, c := cutServer(t, body)
+		s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, err := s.Recv(); err != nil {
+			t.Fatalf("%s: first chunk: %v", name, err)
+		}
+		_, err = s.Recv()
+		var ie *IncompleteStreamError
+		if !errors.As(err, &ie) || ie.Chunks != 1 || ie.Partial.(*GenerateResponse).Response != "a" {
+			t.Fatalf("%s: unexpected err: %#v", name, err)
+		}
+		_ = s.Close()
+		srv.Close()
+	}
+}
+
+func TestStream_UndecodableTailIsIncomplete(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"a\"}\n{\"respo")
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	if _, err := s.Collect(); !errors.Is(err, ErrIncompleteStream) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package ollama
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSlowConsumer is returned by a Tee consumer that fell a full buffer
+// behind under TeeDisconnect.
+var ErrSlowConsumer = errors.New("ollama: tee consumer too slow")
+
+var errTeeClosed = errors.New("ollama: tee consumer closed")
+
+// SlowConsumerPolicy decides what Tee does with a chunk for a consumer whose
+// buffer is full.
+type SlowConsumerPolicy int
+
+const (
+	// TeeBlock waits for the consumer, so every consumer sees every chunk
+	// and the stream moves at the pace of the slowest.
+	TeeBlock SlowConsumerPolicy = iota
+	// TeeDrop skips the chunk for that consumer only. It still receives the
+	// stream's end or error.
+	TeeDrop
+	// TeeDisconnect ends that consumer's stream: it reads the chunks already
+	// buffered, then ErrSlowConsumer. The others carry on.
+	TeeDisconnect
+)
+
+// TeeOptions configures Tee.
+type TeeOptions struct {
+	// Buffer is how many chunks a consumer may fall behind before Policy
+	// applies. Default 16.
+	Buffer int
+	Policy SlowConsumerPolicy
+}
+
+// Tee splits s into n streams that each receive its chunks independently,
+// for example to render, persist and moderate the same chat. Each consumer
+// is a *Stream[T] with the usual Recv, Collect and Close; all of them end
+// with the upstream's EOF or error, including ErrIncompleteStream.
+//
+// Chunks are shared between consumers and must not be modified. Reading
+// starts at once; s is closed when every consumer has been closed, and
+// each consumer must be closed even if it is not read. Tee panics if n < 1.
+func Tee[T any](s *Stream[T], n int, opts TeeOptions) []*Stream[T] {
+	if n < 1 {
+		panic("ollama: Tee needs at least one consumer")
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 16
+	}
+	t := &tee[T]{src: s, policy: opts.Policy}
+	t.open.Store(int32(n))
+	outs := make([]*teeOut[T], n)
+	streams := make([]*Stream[T], n)
+	for i := range outs {
+		o := &teeOut[T]{ch: make(chan *T, opts.Buffer), gone: make(chan struct{})}
+		outs[i] = o
+		streams[i] = &Stream[T]{next: o.recv, closer: teeCloser(func() error { return t.closeOut(o) })}
+	}
+	go t.pump(outs)
+	return streams
+}
+
+type tee[T any] struct {
+	src    *Stream[T]
+	policy SlowConsumerPolicy
+	open   atomic.Int32 // consumers not yet closed
+}
+
+type teeOut[T any] struct {
+	ch   chan *T
+	gone chan struct{} // closed by the consumer's Close
+	once sync.Once
+	err  error // why ch was closed; written by pump before closing ch
+}
+
+type teeCloser func() error
+
+func (f teeCloser) Close() error { return f() }
+
+// pump reads the upstream and fans each chunk out until the upstream ends or
+// no consumer is left to send to.
+func (t *tee[T]) pump(outs []*teeOut[T]) {
+	live := append([]*teeOut[T](nil), outs...)
+	for len(live) > 0 {
+		v, err := t.src.Recv()
+		if err != nil {
+			for _, o := range live {
+				o.err = err
+				close(o.ch)
+			}
+			return
+		}
+		for i := 0; i < len(live); {
+			if t.send(live[i], v) {
+				i++
+				continue
+			}
+			live = append(live[:i], live[i+1:]...)
+		}
+	}
+}
+
+// send delivers v to o and reports whether o should get further chunks.
+func (t *tee[T]) send(o *teeOut[T], v *T) bool {
+	select {
+	case o.ch <- v:
+		return true
+	case <-o.gone:
+		return false
+	default:
+	}
+	switch t.policy {
+	case TeeDrop:
+		return true
+	case TeeDisconnect:
+		o.err = ErrSlowConsumer
+		close(o.ch)
+		return false
+	}
+	select {
+	case o.ch <- v:
+		return true
+	case <-o.gone:
+		return false
+	}
+}
+
+func (o *teeOut[T]) recv() (*T, error) {
+	select {
+	case <-o.gone:
+		return nil, errTeeClosed
+	default:
+	}
+	select {
+	case v, ok := <-o.ch:
+		if !ok {
+			return nil, o.err
+		}
+		return v, nil
+	case <-o.gone:
+		return nil, errTeeClosed
+	}
+}
+
+// closeOut detaches o and closes the upstream once no consumer is left.
+func (t *tee[T]) closeOut(o *teeOut[T]) error {
+	var err error
+	o.once.Do(func() {
+		close(o.gone)
+		if t.open.Add(-1) == 0 {
+			err = t.src.Close()
+		}
+	})
+	return err
+}
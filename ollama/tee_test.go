@@ -0,0 +1,148 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const teeGenerate = `{"response":"a"}
+{"response":"b"}
+{"response":"c"}
+{"response":"d","done":true}
+`
+
+func teeOpen(t *testing.T, body string) *Stream[GenerateResponse] {
+	t.Helper()
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	})
+	t.Cleanup(srv.Close)
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestTee_Block(t *testing.T) {
+	outs := Tee(teeOpen(t, teeGenerate), 3, TeeOptions{Buffer: 1})
+	results := make(chan string, len(outs))
+	for _, s := range outs {
+		go func(s *Stream[GenerateResponse]) {
+			out, err := s.Collect()
+			if err != nil {
+				results <- err.Error()
+				return
+			}
+			results <- out.Response
+		}(s)
+	}
+	for range outs {
+		if got := <-results; got != "abcd" {
+			t.Fatalf("got %q", got)
+		}
+	}
+}
+
+func TestTee_DropAndDisconnect(t *testing.T) {
+	for _, tc := range []struct {
+		policy SlowConsumerPolicy
+		err    error
+	}{
+		{TeeDrop, nil},
+		{TeeDisconnect, ErrSlowConsumer},
+	} {
+		// the server sends each chunk only after the fast consumer read the
+		// previous one, so only the slow consumer falls behind
+		next := make(chan struct{})
+		srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			for i, line := range strings.SplitAfter(teeGenerate, "\n") {
+				if i > 0 {
+					<-next
+				}
+				_, _ = io.WriteString(w, line)
+				w.(http.Flusher).Flush()
+			}
+		})
+		s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		outs := Tee(s, 2, TeeOptions{Buffer: 1, Policy: tc.policy})
+		fast, slow := outs[0], outs[1]
+		var got string
+		for {
+			v, err := fast.Recv()
+			if err == EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got += v.Response
+			next <- struct{}{}
+		}
+		_ = fast.Close()
+		if got != "abcd" {
+			t.Fatalf("policy %d: fast got %q", tc.policy, got)
+		}
+		out, err := slow.Collect()
+		if !errors.Is(err, tc.err) || out.Response != "a" {
+			t.Fatalf("policy %d: slow got %q, %v", tc.policy, out.Response, err)
+		}
+		close(next)
+		srv.Close()
+	}
+}
+
+func TestTee_PropagatesError(t *testing.T) {
+	outs := Tee(teeOpen(t, "{\"response\":\"a\"}\n{\"error\":\"boom\"}\n"), 2, TeeOptions{})
+	for _, s := range outs {
+		out, err := s.Collect()
+		var re *ResponseError
+		if !errors.As(err, &re) || re.Message != "boom" || out.Response != "a" {
+			t.Fatalf("got %v, %v", out, err)
+		}
+	}
+
+	outs = Tee(teeOpen(t, "{\"response\":\"a\"}\n"), 2, TeeOptions{})
+	for _, s := range outs {
+		if _, err := s.Collect(); !errors.Is(err, ErrIncompleteStream) {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+}
+
+func TestTee_CloseAllClosesUpstream(t *testing.T) {
+	gone := make(chan struct{})
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"a\"}\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		close(gone)
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outs := Tee(s, 2, TeeOptions{})
+	if v, err := outs[0].Recv(); err != nil || v.Response != "a" {
+		t.Fatalf("got %v, %v", v, err)
+	}
+	_ = outs[0].Close()
+	if _, err := outs[0].Recv(); err == nil {
+		t.Fatal("Recv after Close succeeded")
+	}
+	select {
+	case <-gone:
+		t.Fatal("upstream closed while a consumer was open")
+	default:
+	}
+	_ = outs[1].Close()
+	<-gone
+}
@@ -0,0 +1,107 @@
+package ollama
+
+import "io"
+
+// TextOptions configures TextReader.
+type TextOptions struct {
+	// Thinking includes the model's reasoning in the text, as it arrives
+	// and without separators. By default only the answer is read.
+	Thinking bool
+}
+
+// StreamReader reads the text of a chat or generate stream. It is not safe
+// for concurrent use.
+type StreamReader struct {
+	events   *EventStream
+	thinking bool
+	pending  string // unread part of the current delta
+	done     *Done
+	err      error // sticky; io.EOF once the stream ended
+}
+
+// TextReader returns an io.ReadCloser over the generated text of s, so it
+// can be copied into files, templates or other writers. Read returns io.EOF
+// once the stream ends and otherwise the stream's error, such as a
+// *ResponseError or ErrIncompleteStream, after the text received before it.
+// Tool calls are skipped. Closing the reader closes s.
+func TextReader[T ChatResponse | GenerateResponse](s *Stream[T], opts TextOptions) *StreamReader {
+	r := &StreamReader{thinking: opts.Thinking}
+	switch s := any(s).(type) {
+	case *Stream[ChatResponse]:
+		r.events = ChatEvents(s)
+	case *Stream[GenerateResponse]:
+		r.events = GenerateEvents(s)
+	}
+	return r
+}
+
+// Read reads generated text into p.
+func (r *StreamReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := r.fill(); err != nil {
+		return 0, err
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// WriteTo writes the remaining text to w one delta at a time, so io.Copy
+// needs no intermediate buffer. It returns nil when the stream ends.
+func (r *StreamReader) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for {
+		if err := r.fill(); err != nil {
+			if err == io.EOF {
+				return total, nil
+			}
+			return total, err
+		}
+		n, err := io.WriteString(w, r.pending)
+		total += int64(n)
+		r.pending = r.pending[n:]
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// fill receives events until there is text to read or the stream ends.
+func (r *StreamReader) fill() error {
+	for r.pending == "" {
+		if r.err != nil {
+			return r.err
+		}
+		ev, err := r.events.Recv()
+		if err != nil {
+			r.err = err
+			continue
+		}
+		switch ev := ev.(type) {
+		case ContentDelta:
+			r.pending = ev.Text
+		case ThinkingDelta:
+			if r.thinking {
+				r.pending = ev.Text
+			}
+		case Done:
+			r.done = &ev
+		}
+	}
+	return nil
+}
+
+// Done returns the final metrics and done_reason, and Context for generate
+// streams, once the final chunk has been read; at the latest when Read has
+// returned io.EOF. ok is false before then or if the stream failed.
+func (r *StreamReader) Done() (done Done, ok bool) {
+	if r.done == nil {
+		return Done{}, false
+	}
+	return *r.done, true
+}
+
+// Close closes the underlying stream.
+func (r *StreamReader) Close() error { return r.events.Close() }
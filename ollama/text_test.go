@@ -0,0 +1,78 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"testing/iotest"
+)
+
+func TestTextReader_Generate(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"response":"Hello"}
+{"response":", world","thinking":"hmm"}
+{"response":"","done":true,"done_reason":"stop","eval_count":2,"context":[1,2]}
+`)
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := TextReader(s, TextOptions{})
+	defer func() { _ = r.Close() }()
+	if _, ok := r.Done(); ok {
+		t.Fatal("Done before reading")
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "Hello, world" {
+		t.Fatalf("got %q", buf.String())
+	}
+	d, ok := r.Done()
+	if !ok || *d.Metrics.EvalCount != 2 || *d.Metrics.DoneReason != "stop" || len(d.Context) != 2 {
+		t.Fatalf("done=%+v ok=%v", d, ok)
+	}
+}
+
+func TestTextReader_ChatThinking(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, reasoningChat)
+	})
+	defer srv.Close()
+	s, err := c.ChatStream(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := TextReader(s, TextOptions{Thinking: true})
+	defer func() { _ = r.Close() }()
+	if err := iotest.TestReader(r, []byte("hmm okHi")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTextReader_SurfacesError(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"response\":\"partial\"}\n{\"error\":\"boom\"}\n")
+	})
+	defer srv.Close()
+	s, err := c.GenerateStream(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := TextReader(s, TextOptions{})
+	defer func() { _ = r.Close() }()
+	b, err := io.ReadAll(r)
+	var re *ResponseError
+	if string(b) != "partial" || !errors.As(err, &re) || re.Message != "boom" {
+		t.Fatalf("got %q, %v", b, err)
+	}
+	if _, ok := r.Done(); ok {
+		t.Fatal("Done after a failed stream")
+	}
+}
@@ -0,0 +1,94 @@
+package ollama
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// tlsOptions collects TLS settings until the transport is built.
+type tlsOptions struct {
+	config     *tls.Config
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
+}
+
+// WithTLSConfig sets the base TLS configuration for https hosts. It is cloned;
+// the other TLS options are applied on top of it.
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(c *Client) { c.tlsOpts.config = cfg }
+}
+
+// WithCACertFile trusts the PEM certificates in path in addition to the
+// system roots. Defaults to OLLAMA_CA_CERT.
+func WithCACertFile(path string) ClientOption {
+	return func(c *Client) { c.tlsOpts.caFile = path }
+}
+
+// WithClientCertificate presents the PEM certificate and key for mutual TLS.
+// Defaults to OLLAMA_CLIENT_CERT and OLLAMA_CLIENT_KEY.
+func WithClientCertificate(certFile, keyFile string) ClientOption {
+	return func(c *Client) { c.tlsOpts.certFile, c.tlsOpts.keyFile = certFile, keyFile }
+}
+
+// WithTLSServerName sets the SNI name and the name verified against the
+// server certificate, for hosts reached by IP or through a tunnel.
+func WithTLSServerName(name string) ClientOption {
+	return func(c *Client) { c.tlsOpts.serverName = name }
+}
+
+// explicit reports whether TLS was configured by options rather than only
+// by environment variables.
+func (o *tlsOptions) explicit() bool {
+	return o.config != nil || o.caFile != "" || o.certFile != "" || o.keyFile != "" || o.serverName != ""
+}
+
+// build returns the TLS configuration to install, or nil when nothing was
+// configured by options or environment.
+func (o *tlsOptions) build() (*tls.Config, error) {
+	caFile := o.caFile
+	if caFile == "" {
+		caFile = os.Getenv("OLLAMA_CA_CERT")
+	}
+	certFile, keyFile := o.certFile, o.keyFile
+	if certFile == "" && keyFile == "" {
+		certFile, keyFile = os.Getenv("OLLAMA_CLIENT_CERT"), os.Getenv("OLLAMA_CLIENT_KEY")
+	}
+	if o.config == nil && caFile == "" && certFile == "" && keyFile == "" && o.serverName == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.config != nil {
+		cfg = o.config.Clone()
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("ollama: reading CA certificates: %w", err)
+		}
+		pool := cfg.RootCAs
+		if pool == nil {
+			if pool, err = x509.SystemCertPool(); err != nil {
+				pool = x509.NewCertPool()
+			}
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ollama: no certificates found in %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("ollama: loading client certificate: %w", err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+	if o.serverName != "" {
+		cfg.ServerName = o.serverName
+	}
+	return cfg, nil
+}
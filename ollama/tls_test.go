@@ -0,0 +1,184 @@
+package ollama
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPKI struct {
+	dir      string
+	caFile   string
+	caPool   *x509.CertPool
+	ca       *x509.Certificate
+	caKey    *ecdsa.PrivateKey
+	serverTL tls.Certificate
+}
+
+// newTestPKI creates a CA and a server certificate valid for 127.0.0.1 and
+// the name "ollama.internal".
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	p := &testPKI{dir: t.TempDir()}
+	p.caKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &p.caKey.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ca, _ = x509.ParseCertificate(der)
+	p.caPool = x509.NewCertPool()
+	p.caPool.AddCert(p.ca)
+	p.caFile = p.write(t, "ca.pem", "CERTIFICATE", der)
+
+	certFile, keyFile := p.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	p.serverTL, err = tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func (p *testPKI) write(t *testing.T, name, typ string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(p.dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// issue signs a leaf certificate and returns its cert and key files.
+func (p *testPKI) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"ollama.internal"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, p.ca, &key.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kder, _ := x509.MarshalECPrivateKey(key)
+	return p.write(t, name+".pem", "CERTIFICATE", der), p.write(t, name+"-key.pem", "EC PRIVATE KEY", kder)
+}
+
+// newMTLSServer starts a TLS server that requires a client certificate
+// signed by the test CA and reports the client's common name.
+func newMTLSServer(t *testing.T, p *testPKI) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cn := r.TLS.PeerCertificates[0].Subject.CommonName
+		_, _ = io.WriteString(w, `{"models":[{"model":"`+cn+`"}]}`)
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{p.serverTL},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    p.caPool,
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTLS_MutualAuth(t *testing.T) {
+	p := newTestPKI(t)
+	srv := newMTLSServer(t, p)
+	certFile, keyFile := p.issue(t, "client", x509.ExtKeyUsageClientAuth)
+
+	c := NewClient(srv.URL, WithCACertFile(p.caFile), WithClientCertificate(certFile, keyFile))
+	out, err := c.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Models) != 1 || *out.Models[0].Model != "client" {
+		t.Fatalf("unexpected: %+v", out)
+	}
+
+	noCert := NewClient(srv.URL, WithCACertFile(p.caFile))
+	if _, err := noCert.List(context.Background()); err == nil {
+		t.Fatal("expected handshake failure without client certificate")
+	}
+}
+
+func TestTLS_EnvAndServerName(t *testing.T) {
+	p := newTestPKI(t)
+	srv := newMTLSServer(t, p)
+	certFile, keyFile := p.issue(t, "envclient", x509.ExtKeyUsageClientAuth)
+	for k, v := range map[string]string{"OLLAMA_CA_CERT": p.caFile, "OLLAMA_CLIENT_CERT": certFile, "OLLAMA_CLIENT_KEY": keyFile} {
+		old := os.Getenv(k)
+		_ = os.Setenv(k, v)
+		defer func(k, old string) { _ = os.Setenv(k, old) }(k, old)
+	}
+	// Reach the server under its DNS name by dialing the listener directly.
+	addr := strings.TrimPrefix(srv.URL, "https://")
+	var d net.Dialer
+	c := NewClient("https://ollama.internal:443", WithTLSServerName("ollama.internal"), WithDialer(func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return d.DialContext(ctx, network, addr)
+	}))
+	out, err := c.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *out.Models[0].Model != "envclient" {
+		t.Fatalf("unexpected: %+v", out)
+	}
+}
+
+func TestTLS_BadCAFileSurfacesOnCall(t *testing.T) {
+	c := NewClient("https://127.0.0.1:1", WithCACertFile(filepath.Join(t.TempDir(), "missing.pem")))
+	_, err := c.List(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "CA certificates") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestTLS_CustomTransportFails(t *testing.T) {
+	var sent int
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		sent++
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"models":[]}`)), Request: r}, nil
+	})
+	for name, opt := range map[string]ClientOption{
+		"server name": WithTLSServerName("ollama.internal"),
+		"config":      WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13}),
+	} {
+		c := NewClient("https://ollama.internal", WithHTTPClient(&http.Client{Transport: rt}), opt)
+		if _, err := c.List(context.Background()); err == nil || !strings.Contains(err.Error(), "TLS options") {
+			t.Errorf("%s: unexpected err: %v", name, err)
+		}
+	}
+	if sent != 0 {
+		t.Fatalf("%d requests sent without the requested TLS settings", sent)
+	}
+}
@@ -0,0 +1,276 @@
+package ollama
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Tracer starts spans for API calls. It is a dependency-free hook; see the
+// ollamaotel module for an OpenTelemetry adapter.
+//
+// One span covers each call from the first attempt until the response body
+// is consumed, or for streams until Close. Spans carry these attributes when
+// known: "http.request.method", "url.path", "server.address",
+// "ollama.model", "ollama.stream", "http.response.status_code",
+// "ollama.prompt_eval_count", "ollama.eval_count",
+// "ollama.total_duration_ns", "ollama.done_reason", "ollama.chunks" and
+// "ollama.time_to_first_chunk_ms". Events are "retry" and "first_chunk".
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a unit of work started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	AddEvent(name string, attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key/value pair attached to spans and events. Values are
+// strings, bools, ints, int64s or float64s.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// WithTracer reports every call to t.
+func WithTracer(t Tracer) ClientOption {
+	return func(c *Client) { c.tracer = t }
+}
+
+// callTrace follows one API call for the tracer, logger and metrics. A nil
+// *callTrace ignores every method, so call sites need not check whether any
+// of them is enabled.
+type callTrace struct {
+	span    Span // nil when not tracing
+	log     *slog.Logger
+	metrics *Metrics
+	req     *Request
+	start   time.Time
+	stream  bool
+
+	mu         sync.Mutex
+	status     int
+	host       string
+	bytes      int64
+	chunks     int
+	first      time.Duration
+	doneReason string
+	result     *BaseGenerateResponse // final generate/chat metrics
+	failed     bool                  // the call or its stream returned an error
+	once       sync.Once
+}
+
+// startTrace opens a span for req, or returns nil when no tracer, logger or
+// metrics are set.
+func (c *Client) startTrace(ctx context.Context, req *Request) (context.Context, *callTrace) {
+	if c.tracer == nil && c.logger == nil && c.metrics == nil {
+		return ctx, nil
+	}
+	t := &callTrace{log: c.logger, metrics: c.metrics, req: req, start: time.Now(), stream: req.Stream}
+	if c.tracer != nil {
+		attrs := []Attribute{
+			{Key: "http.request.method", Value: req.Method},
+			{Key: "url.path", Value: req.Path},
+			{Key: "ollama.stream", Value: req.Stream},
+		}
+		if req.Model != "" {
+			attrs = append(attrs, Attribute{Key: "ollama.model", Value: req.Model})
+		}
+		ctx, t.span = c.tracer.Start(ctx, "ollama "+req.Path, attrs...)
+	}
+	return ctx, t
+}
+
+// attempt records the host and status of the latest attempt; resp is nil
+// when the attempt failed without a response.
+func (t *callTrace) attempt(resp *http.Response) {
+	if t == nil {
+		return
+	}
+	status, host := 0, ""
+	if resp != nil {
+		status = resp.StatusCode
+		if resp.Request != nil && resp.Request.URL != nil {
+			host = resp.Request.URL.Host
+		}
+	}
+	t.mu.Lock()
+	t.status, t.host = status, host
+	t.mu.Unlock()
+	if t.span != nil && resp != nil {
+		t.span.SetAttributes(Attribute{Key: "http.response.status_code", Value: status}, Attribute{Key: "server.address", Value: host})
+	}
+}
+
+func (t *callTrace) retry(ev RetryEvent) {
+	if t == nil {
+		return
+	}
+	if t.metrics != nil {
+		t.metrics.retry(t.req)
+	}
+	if t.log != nil {
+		t.log.Warn("ollama retry", t.logAttrs(slog.Int("attempt", ev.Attempt), slog.Int("status", ev.StatusCode), slog.Duration("delay", ev.Delay), errAttr(ev.Err))...)
+	}
+	if t.span == nil {
+		return
+	}
+	attrs := []Attribute{{Key: "attempt", Value: ev.Attempt}, {Key: "delay_ms", Value: ev.Delay.Milliseconds()}}
+	if ev.StatusCode > 0 {
+		attrs = append(attrs, Attribute{Key: "http.response.status_code", Value: ev.StatusCode})
+	}
+	if ev.Err != nil {
+		attrs = append(attrs, Attribute{Key: "error", Value: ev.Err.Error()})
+	}
+	t.span.AddEvent("retry", attrs...)
+}
+
+// opened records a successful response, before its body is read.
+func (t *callTrace) opened() {
+	if t == nil || t.log == nil || !t.stream {
+		return
+	}
+	t.log.Debug("ollama stream open", t.logAttrs(slog.Int("status", t.status), slog.String("host", t.host), slog.Duration("latency", time.Since(t.start)))...)
+}
+
+// read counts response body bytes.
+func (t *callTrace) read(n int) {
+	t.mu.Lock()
+	t.bytes += int64(n)
+	t.mu.Unlock()
+}
+
+// chunk records a decoded stream chunk, noting time to first chunk and the
+// final metrics.
+func (t *callTrace) chunk(v any) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.chunks++
+	first := t.chunks == 1
+	if first {
+		t.first = time.Since(t.start)
+	}
+	t.mu.Unlock()
+	if first && t.span != nil {
+		t.span.AddEvent("first_chunk", Attribute{Key: "ollama.time_to_first_chunk_ms", Value: t.first.Milliseconds()})
+	}
+	t.response(v)
+}
+
+// response records token accounting from a generate or chat response.
+func (t *callTrace) response(v any) {
+	if t == nil {
+		return
+	}
+	m, ok := v.(interface{ metrics() *BaseGenerateResponse })
+	if !ok {
+		return
+	}
+	b := m.metrics()
+	if b.Done == nil || !*b.Done {
+		return
+	}
+	t.mu.Lock()
+	t.result = b
+	if b.DoneReason != nil {
+		t.doneReason = *b.DoneReason
+	}
+	t.mu.Unlock()
+	if t.span == nil {
+		return
+	}
+	var attrs []Attribute
+	if b.PromptEvalCount != nil {
+		attrs = append(attrs, Attribute{Key: "ollama.prompt_eval_count", Value: *b.PromptEvalCount})
+	}
+	if b.EvalCount != nil {
+		attrs = append(attrs, Attribute{Key: "ollama.eval_count", Value: *b.EvalCount})
+	}
+	if b.TotalDuration != nil {
+		attrs = append(attrs, Attribute{Key: "ollama.total_duration_ns", Value: *b.TotalDuration})
+	}
+	if b.DoneReason != nil {
+		attrs = append(attrs, Attribute{Key: "ollama.done_reason", Value: *b.DoneReason})
+	}
+	if len(attrs) > 0 {
+		t.span.SetAttributes(attrs...)
+	}
+}
+
+func (t *callTrace) fail(err error) {
+	if t == nil || err == nil {
+		return
+	}
+	t.mu.Lock()
+	t.failed = true
+	t.mu.Unlock()
+	if t.log != nil {
+		t.log.Warn("ollama error", t.logAttrs(slog.Int("status", t.status), slog.Duration("latency", time.Since(t.start)), errAttr(err))...)
+	}
+	if t.span != nil {
+		t.span.RecordError(err)
+	}
+}
+
+// end closes the span once; later calls are ignored.
+func (t *callTrace) end(err error) {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() {
+		t.fail(err)
+		t.mu.Lock()
+		chunks, first, doneReason, n := t.chunks, t.first, t.doneReason, t.bytes
+		status, host, result, failed := t.status, t.host, t.result, t.failed
+		t.mu.Unlock()
+		if t.metrics != nil {
+			t.metrics.observe(t.req, status, failed, time.Since(t.start), first, result)
+		}
+		if t.log != nil && err == nil {
+			if t.stream {
+				t.log.Debug("ollama stream close", t.logAttrs(slog.Int("chunks", chunks), slog.String("done_reason", doneReason), slog.Int64("bytes", n), slog.Duration("duration", time.Since(t.start)))...)
+			} else {
+				t.log.Debug("ollama response", t.logAttrs(slog.Int("status", status), slog.String("host", host), slog.Int64("bytes", n), slog.Duration("latency", time.Since(t.start)))...)
+			}
+		}
+		if t.span == nil {
+			return
+		}
+		if t.stream {
+			attrs := []Attribute{{Key: "ollama.chunks", Value: chunks}}
+			if chunks > 0 {
+				attrs = append(attrs, Attribute{Key: "ollama.time_to_first_chunk_ms", Value: first.Milliseconds()})
+			}
+			t.span.SetAttributes(attrs...)
+		}
+		t.span.End()
+	})
+}
+
+// tracedBody counts response bytes and ends the call's trace on Close.
+type tracedBody struct {
+	io.ReadCloser
+	t *callTrace
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.t.read(n)
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.t.end(nil)
+	return err
+}
+
+func (b *BaseGenerateResponse) metrics() *BaseGenerateResponse { return b }
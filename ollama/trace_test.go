@@ -0,0 +1,138 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedSpan struct {
+	name   string
+	attrs  map[string]any
+	events []string
+	errs   []error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+func (s *recordedSpan) AddEvent(name string, _ ...Attribute) { s.events = append(s.events, name) }
+func (s *recordedSpan) RecordError(err error)                { s.errs = append(s.errs, err) }
+func (s *recordedSpan) End()                                 { s.ended = true }
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &recordedSpan{name: name, attrs: map[string]any{}}
+	s.SetAttributes(attrs...)
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return ctx, s
+}
+
+func TestTracer_GenerateRecordsTokens(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"response":"hi","done":true,"done_reason":"stop","prompt_eval_count":7,"eval_count":3,"total_duration":1000}`)
+	})
+	defer srv.Close()
+	tr := &recordingTracer{}
+	WithTracer(tr)(c)
+
+	if _, err := c.Generate(context.Background(), &GenerateRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "llama3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.spans) != 1 {
+		t.Fatalf("spans: %d", len(tr.spans))
+	}
+	s := tr.spans[0]
+	if !s.ended || s.name != "ollama /api/generate" {
+		t.Fatalf("span: %+v", s)
+	}
+	want := map[string]any{
+		"ollama.model":              "llama3",
+		"url.path":                  "/api/generate",
+		"http.response.status_code": 200,
+		"ollama.prompt_eval_count":  7,
+		"ollama.eval_count":         3,
+		"ollama.done_reason":        "stop",
+	}
+	for k, v := range want {
+		if s.attrs[k] != v {
+			t.Fatalf("%s = %v, want %v (attrs %v)", k, s.attrs[k], v, s.attrs)
+		}
+	}
+	if s.attrs["server.address"] == "" {
+		t.Fatal("missing server.address")
+	}
+}
+
+func TestTracer_StreamFirstChunkAndClose(t *testing.T) {
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "{\"message\":{\"role\":\"assistant\",\"content\":\"a\"}}\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(10 * time.Millisecond)
+		_, _ = io.WriteString(w, "{\"message\":{\"role\":\"assistant\",\"content\":\"b\"},\"done\":true,\"eval_count\":2}\n")
+	})
+	defer srv.Close()
+	tr := &recordingTracer{}
+	WithTracer(tr)(c)
+
+	s, err := c.ChatStream(context.Background(), &ChatRequest{BaseStreamableRequest: BaseStreamableRequest{Model: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := s.Recv(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	span := tr.spans[0]
+	if span.ended {
+		t.Fatal("span ended before Close")
+	}
+	_ = s.Close()
+	if !span.ended || span.attrs["ollama.chunks"] != 2 || span.attrs["ollama.eval_count"] != 2 || span.attrs["ollama.stream"] != true {
+		t.Fatalf("span: %+v", span)
+	}
+	if len(span.events) != 1 || span.events[0] != "first_chunk" {
+		t.Fatalf("events: %v", span.events)
+	}
+}
+
+func TestTracer_RetryAndError(t *testing.T) {
+	var calls int
+	srv, c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, `{"error":"busy"}`)
+	})
+	defer srv.Close()
+	tr := &recordingTracer{}
+	WithTracer(tr)(c)
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1, RetryStatusCodes: []int{503}})(c)
+
+	if _, err := c.List(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+	s := tr.spans[0]
+	if calls != 2 || len(s.events) != 1 || s.events[0] != "retry" {
+		t.Fatalf("calls=%d events=%v", calls, s.events)
+	}
+	if !s.ended || len(s.errs) != 1 || s.attrs["http.response.status_code"] != 503 {
+		t.Fatalf("span: %+v", s)
+	}
+}
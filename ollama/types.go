@@ -346,3 +346,6 @@ type ProcessModel struct {
 
 // StrPtr returns a pointer to the given string.
 func StrPtr(s string) *string { return &s }
+
+// BoolPtr returns a pointer to the given bool.
+func BoolPtr(b bool) *bool { return &b }
@@ -0,0 +1,71 @@
+// Package ollamaotel adapts OpenTelemetry tracing to the ollama client's
+// dependency-free Tracer hook. It is a separate module so the core client
+// does not depend on OpenTelemetry.
+//
+//	c := ollama.NewClient("", ollama.WithTracer(ollamaotel.NewTracer(nil)))
+package ollamaotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/phaedrusllc/ollama-go/ollama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/phaedrusllc/ollama-go/otel"
+
+// NewTracer returns an ollama.Tracer that starts client spans from tp, or
+// from the global TracerProvider when tp is nil.
+func NewTracer(tp trace.TracerProvider) ollama.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &tracer{t: tp.Tracer(instrumentationName)}
+}
+
+type tracer struct{ t trace.Tracer }
+
+func (t *tracer) Start(ctx context.Context, name string, attrs ...ollama.Attribute) (context.Context, ollama.Span) {
+	ctx, s := t.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(convert(attrs)...))
+	return ctx, span{s}
+}
+
+type span struct{ s trace.Span }
+
+func (s span) SetAttributes(attrs ...ollama.Attribute) { s.s.SetAttributes(convert(attrs)...) }
+
+func (s span) AddEvent(name string, attrs ...ollama.Attribute) {
+	s.s.AddEvent(name, trace.WithAttributes(convert(attrs)...))
+}
+
+func (s span) RecordError(err error) {
+	s.s.RecordError(err)
+	s.s.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() { s.s.End() }
+
+func convert(attrs []ollama.Attribute) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			out = append(out, attribute.String(a.Key, v))
+		case bool:
+			out = append(out, attribute.Bool(a.Key, v))
+		case int:
+			out = append(out, attribute.Int(a.Key, v))
+		case int64:
+			out = append(out, attribute.Int64(a.Key, v))
+		case float64:
+			out = append(out, attribute.Float64(a.Key, v))
+		default:
+			out = append(out, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return out
+}
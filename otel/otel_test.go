@@ -0,0 +1,44 @@
+package ollamaotel
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phaedrusllc/ollama-go/ollama"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer_ExportsClientSpan(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"message":{"role":"assistant","content":"hi"},"done":true,"prompt_eval_count":5,"eval_count":2}`)
+	}))
+	defer srv.Close()
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	c := ollama.NewClient(srv.URL, ollama.WithTracer(NewTracer(tp)))
+	if _, err := c.Chat(context.Background(), &ollama.ChatRequest{BaseStreamableRequest: ollama.BaseStreamableRequest{Model: "m"}}); err != nil {
+		t.Fatal(err)
+	}
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("spans: %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name() != "ollama /api/chat" || s.SpanKind() != trace.SpanKindClient {
+		t.Fatalf("span %q kind %v", s.Name(), s.SpanKind())
+	}
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes() {
+		got[kv.Key] = kv.Value
+	}
+	if got["ollama.model"].AsString() != "m" || got["ollama.prompt_eval_count"].AsInt64() != 5 || got["http.response.status_code"].AsInt64() != 200 {
+		t.Fatalf("attrs: %v", got)
+	}
+}